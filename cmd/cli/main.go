@@ -6,9 +6,19 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
 	"github.com/AzraelSec/mad-aliens/pkg/engine"
+	"github.com/AzraelSec/mad-aliens/pkg/scenario"
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+	"github.com/AzraelSec/mad-aliens/pkg/world/analysis"
+	"github.com/AzraelSec/mad-aliens/pkg/world/compact"
 )
 
 const (
@@ -17,44 +27,334 @@ const (
 )
 
 var (
-	i = flag.String("i", "", "input file to read world definition from")
-	m = flag.Int("m", DEFAULT_MAX_ROUND, "max number of rounds to run")
-	n = flag.Int("n", DEFAULT_ALINES_N, "number of aliens to deploy")
+	i            = flag.String("i", "", "input file to read world definition from")
+	scenarioFile = flag.String("scenario", "", "scenario file to read the whole invasion setup from, in place of the other flags (JSON)")
+	seed         = flag.Int64("seed", 0, "seed of the random generator, to reproduce an invasion (random if zero)")
+	m            = flag.Int("m", DEFAULT_MAX_ROUND, "max number of rounds to run")
+	n            = flag.Int("n", DEFAULT_ALINES_N, "number of aliens to deploy")
+
+	exportBefore = flag.String("export-dot", "", "file to export the world graph to before the invasion (.dot or .svg)")
+	exportAfter  = flag.String("export-after", "", "file to export the world graph to after the invasion (.dot or .svg)")
+
+	analyze    = flag.Bool("analyze", false, "print the world map analysis without running the invasion")
+	compactRun = flag.Bool("compact", false, "run the invasion on the compact world representation, only supporting the default rules")
+	workers    = flag.Int("workers", 0, "number of goroutines choosing the aliens moves in parallel (sequential if zero)")
+
+	names       = flag.String("names", "", "file to read alien identities from (one per line: name [species=...] [faction=...] [key=value...])")
+	randomNames = flag.Bool("random-names", false, "give a random name to the aliens without an identity")
+
+	factions  = flag.String("factions", "", "comma separated faction shares to split the aliens into (e.g. red=0.5,blue=0.5)")
+	relations = flag.String("relations", "", "comma separated relationships between factions (e.g. red/blue=neutral:0.3,red/green=allies)")
+
+	movement        = flag.String("movement", world.UNIFORM_MOVEMENT, "default movement strategy of the aliens: "+strings.Join(world.MovementStrategies, ", ")+" (e.g. lazy:0.3, biased:north=3/east=2)")
+	factionMovement = flag.String("faction-movement", "", "comma separated movement strategies per faction (e.g. red=hunter,blue=lazy:0.5)")
+
+	headOn    = flag.Bool("head-on", false, "make the aliens traveling the same road in opposite directions fight")
+	wait      = flag.Float64("wait", 0, "probability of each alien to stay in its city for a round instead of moving")
+	pathLimit = flag.Int("path-limit", world.DefaultPathLimit, "max number of steps recorded in each alien path (negative for unbounded)")
+
+	combat          = flag.String("combat", engine.MUTUAL_COMBAT, "fight resolution: "+strings.Join(engine.CombatModes, ", "))
+	rebuild         = flag.Int("rebuild", 0, "number of rounds after which an empty destroyed city gets rebuilt (0 to never rebuild)")
+	damageThreshold = flag.Int("damage-threshold", 0, "damage above which a probabilistic fight destroys the city")
+
+	defenders        = flag.Int("defenders", 0, "number of human defenders to deploy")
+	defenderMovement = flag.String("defender-movement", "", "movement strategy of the defenders (the default one if empty)")
+
+	waves       = flag.String("waves", "", "comma separated waves of aliens arriving during the invasion, as round:count[@city/city] (e.g. 3:5@Foo/Bar,10:8)")
+	spawnRate   = flag.Float64("spawn-rate", 0, "number of aliens arriving each round (e.g. 0.5 for an alien every two rounds)")
+	spawnPoints = flag.String("spawn-points", "", "slash separated cities the continuous spawn happens in (any city if empty)")
+	spawnUntil  = flag.Int("spawn-until", 0, "last round of the continuous spawn (0 for no limit)")
+
+	reportFormat = flag.String("report-format", engine.TEXT_REPORT, "format of the end-of-run report: "+strings.Join(engine.ReportFormats, ", "))
+	trajectories = flag.Bool("trajectories", false, "include the path followed by each alien in the JSON report")
 )
 
-func init() {
-	flag.Parse()
+// Flags that still apply when the invasion is read from a scenario, while the others describe the invasion
+var scenarioFlags = map[string]bool{
+	"scenario":      true,
+	"seed":          true,
+	"export-dot":    true,
+	"export-after":  true,
+	"analyze":       true,
+	"compact":       true,
+	"workers":       true,
+	"report-format": true,
+	"trajectories":  true,
 }
 
 func main() {
-	// world definition file is required
-	if *i == "" {
+	flag.Parse()
+
+	// world definition file is required, unless a scenario provides it
+	if *i == "" && *scenarioFile == "" {
 		flag.Usage()
 		return
 	}
 
+	// The report format is checked in advance, so that a typo does not waste a whole run
+	if _, err := (&engine.RunReport{}).Render(*reportFormat); err != nil {
+		log.Fatal(err)
+	}
+
+	// The compact mode only prints the final statistics
+	if *compactRun && (*reportFormat != engine.TEXT_REPORT || *exportAfter != "") {
+		log.Fatal("The compact mode supports neither report formats nor exports after the invasion")
+	}
+
+	var execEngine *engine.Engine
+	runSeed := *seed
+	if *scenarioFile != "" {
+		for _, name := range ignoredFlags(flag.CommandLine) {
+			log.Printf("The -%s flag is ignored, since the scenario describes the invasion", name)
+		}
+		var err error
+		if execEngine, runSeed, err = engineFromScenario(*scenarioFile, *seed); err != nil {
+			log.Fatal(err)
+		}
+		// The analysis only needs the map, which the scenario provides
+		if *analyze {
+			fmt.Println(analysis.Analyze(execEngine.World))
+			return
+		}
+	} else if execEngine = engineFromFlags(); execEngine == nil {
+		return
+	}
+
+	execEngine.Workers = *workers
+	execEngine.Trajectories = *trajectories
+
+	// Roads whose direction contradicts the others are reported, since they usually are typos
+	for _, c := range execEngine.World.Layout().Contradictions {
+		log.Printf("Inconsistent road: %s", c)
+	}
+
+	if *exportBefore != "" {
+		if err := exportWorld(*exportBefore, execEngine.World); err != nil {
+			log.Fatalf("Impossible to export world to %s: %s", *exportBefore, err)
+		}
+	}
+
+	if *compactRun {
+		runCompact(execEngine, runSeed)
+		return
+	}
+
+	report, err := execEngine.Run()
+	if err != nil {
+		log.Fatal(err)
+	} else {
+		log.Printf("Execution completed: %s", engine.ExecStatusString(report.Status))
+		out, err := report.Render(*reportFormat)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(out)
+	}
+
+	if *exportAfter != "" {
+		if err := exportWorld(*exportAfter, execEngine.World); err != nil {
+			log.Fatalf("Impossible to export world to %s: %s", *exportAfter, err)
+		}
+	}
+}
+
+// Function that returns the flags explicitly set that do not apply to the invasions read from a scenario
+func ignoredFlags(set *flag.FlagSet) []string {
+	ignored := make([]string, 0)
+	set.Visit(func(f *flag.Flag) {
+		if !scenarioFlags[f.Name] {
+			ignored = append(ignored, f.Name)
+		}
+	})
+	return ignored
+}
+
+// Function that builds the engine from a scenario file.
+// The given seed (if not zero) is used when the scenario has none, and the seed of the invasion is returned along with
+// the engine.
+func engineFromScenario(p string, seed int64) (*engine.Engine, int64, error) {
+	s, err := scenario.Load(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case s.Seed == nil && seed != 0:
+		s.Seed = &seed
+	case s.Seed != nil && seed != 0 && *s.Seed != seed:
+		log.Printf("The -seed flag is ignored, since the scenario has its own seed (%d)", *s.Seed)
+	}
+
+	e, err := s.Engine()
+	if err != nil {
+		return nil, 0, fmt.Errorf("an error occurred during scenario initialization: %w", err)
+	}
+	if s.Seed != nil {
+		seed = *s.Seed
+	}
+	return e, seed, nil
+}
+
+// Function that builds the engine from the world definition file and the flags describing the invasion.
+// Nil is returned when no invasion has to run.
+func engineFromFlags() *engine.Engine {
+	if *seed != 0 {
+		utils.Seed(*seed)
+	}
+
 	file, err := readFile(*i)
 	if err != nil {
 		log.Fatalf("Impossible to read file %s: %s", *i, err)
 	}
 
+	// The analysis only needs the map, so no aliens are deployed
+	if *analyze {
+		w, err := world.Parse(file, 0)
+		if err != nil {
+			log.Fatalf("An error occurred during world parsing: %s", err)
+		}
+		fmt.Println(analysis.Analyze(w))
+		return nil
+	}
+
 	execEngine, err := engine.NewEngine(*n, *m, file)
 	if err != nil {
 		log.Fatalf("An error occurred during engine initialization: %s", err)
 	}
 
-	status, err := execEngine.Run()
+	if *names != "" || *randomNames {
+		identities := make([]alien.Identity, 0)
+		if *names != "" {
+			namesFile, err := readFile(*names)
+			if err != nil {
+				log.Fatalf("Impossible to read file %s: %s", *names, err)
+			}
+			if identities, err = alien.ParseIdentities(namesFile); err != nil {
+				log.Fatalf("An error occurred during identities parsing: %s", err)
+			}
+		}
+		execEngine.World.AssignIdentities(identities, *randomNames)
+	}
+
+	shares, err := world.ParseFactionShares(*factions)
 	if err != nil {
+		log.Fatalf("An error occurred during factions parsing: %s", err)
+	}
+	execEngine.World.AssignFactions(shares)
+
+	if execEngine.Diplomacy, err = engine.ParseDiplomacy(*relations); err != nil {
+		log.Fatalf("An error occurred during relationships parsing: %s", err)
+	}
+
+	if *wait < 0 || *wait > 1 {
+		log.Fatalf("Invalid wait probability: %f", *wait)
+	}
+	execEngine.WaitProbability = *wait
+	execEngine.HeadOnCollisions = *headOn
+	execEngine.RebuildAfter = *rebuild
+	if execEngine.Spawn, err = spawnSchedule(execEngine.World); err != nil {
+		log.Fatalf("An error occurred during spawn schedule parsing: %s", err)
+	}
+	execEngine.World.PathLimit = *pathLimit
+	if err := deployDefenders(execEngine.World); err != nil {
+		log.Fatalf("An error occurred during defenders deployment: %s", err)
+	}
+	if err := assignStrategies(execEngine.World); err != nil {
+		log.Fatalf("An error occurred during movement strategies parsing: %s", err)
+	}
+
+	if execEngine.Combat, err = engine.NewCombatResolver(*combat, *damageThreshold); err != nil {
 		log.Fatal(err)
-	} else {
-		log.Printf("Execution completed: %s", engine.ExecStatusString(status))
-		log.Printf(
-			"| %d survived aliens | %d stuck aliens |",
-			execEngine.World.CountAliveAliens(),
-			execEngine.World.StuckAliens,
-		)
-		fmt.Println(execEngine.World)
 	}
+
+	return execEngine
+}
+
+// Function that assigns the movement strategies to the aliens.
+// Besides the default and per faction strategies, each alien can have its own through the movement attribute of its identity.
+func assignStrategies(w *world.World) (err error) {
+	if w.DefaultStrategy, err = world.ParseMovementStrategy(*movement); err != nil {
+		return err
+	}
+	if w.FactionStrategies, err = world.ParseFactionStrategies(*factionMovement); err != nil {
+		return err
+	}
+	if *defenderMovement != "" {
+		if w.DefenderStrategy, err = world.ParseMovementStrategy(*defenderMovement); err != nil {
+			return err
+		}
+	}
+	for _, id := range w.SortedAlienIds() {
+		if spec, exists := w.Aliens[id].Attributes["movement"]; exists {
+			strategy, err := world.ParseMovementStrategy(spec)
+			if err != nil {
+				return err
+			}
+			w.SetStrategy(id, strategy)
+		}
+	}
+	return nil
+}
+
+// Function that deploys the defenders in random cities
+func deployDefenders(w *world.World) error {
+	cities := w.SortedCityNames()
+	for i := 0; i < *defenders && len(cities) > 0; i++ {
+		d, err := w.SpawnDefender(cities[utils.RandomInt(len(cities))])
+		if err != nil {
+			return err
+		}
+		log.Printf("%s is located at %s", d, d.City.Name)
+	}
+	return nil
+}
+
+// Function that builds the schedule of the aliens arriving during the invasion, if any
+func spawnSchedule(w *world.World) (*engine.SpawnSchedule, error) {
+	parsed, err := engine.ParseWaves(*waves)
+	if err != nil {
+		return nil, err
+	}
+	if *spawnRate < 0 {
+		return nil, fmt.Errorf("invalid spawn rate: %f", *spawnRate)
+	}
+	if len(parsed) == 0 && *spawnRate == 0 {
+		return nil, nil
+	}
+
+	s := &engine.SpawnSchedule{Waves: parsed, Rate: *spawnRate, Until: *spawnUntil}
+	if *spawnPoints != "" {
+		s.Cities = strings.Split(*spawnPoints, "/")
+	}
+	if err := s.Validate(w); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Function that runs the invasion on the compact representation of the engine world, printing its statistics.
+// The random generator is seeded with the given seed, or a random one if zero.
+func runCompact(e *engine.Engine, source int64) {
+	w, err := compact.FromEngine(e)
+	if err != nil {
+		log.Fatalf("The invasion cannot run in compact mode: %s", err)
+	}
+
+	if source == 0 {
+		source = time.Now().UnixNano()
+	}
+	rounds, status := w.Run(e.MaxRuns-e.Runs, rand.New(rand.NewSource(source)))
+	log.Printf("Execution completed: %s", engine.ExecStatusString(status))
+
+	s := w.Stats()
+	fmt.Printf("Rounds executed: %d\n", rounds)
+	fmt.Printf(
+		"| %d survived aliens | %d stuck aliens | %d destroyed aliens | %d surviving cities | %d destroyed cities |\n",
+		s.AliveAliens,
+		s.StuckAliens,
+		s.DestroyedAliens,
+		s.SurvivingCities,
+		s.DestroyedCities,
+	)
 }
 
 func readFile(p string) (io.Reader, error) {
@@ -65,3 +365,23 @@ func readFile(p string) (io.Reader, error) {
 
 	return bufio.NewReader(f), nil
 }
+
+// Function that exports the world graph into a file.
+// The output format is inferred from the file extension: SVG for .svg files, Graphviz DOT otherwise.
+func exportWorld(p string, w *world.World) (err error) {
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	// A failed close can lose buffered data, so it must be reported when the write succeeded
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if strings.EqualFold(filepath.Ext(p), ".svg") {
+		return w.WriteSVG(f)
+	}
+	return w.WriteDOT(f)
+}
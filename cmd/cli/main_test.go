@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/engine"
+)
+
+// Function that writes a scenario deploying 10 aliens among 30 cities, with the given seed field (if any)
+func writeScenario(t *testing.T, seedField string) string {
+	cities := make([]string, 30)
+	for i := range cities {
+		cities[i] = fmt.Sprintf("C%d", i)
+	}
+	p := filepath.Join(t.TempDir(), "scenario.json")
+	content := fmt.Sprintf(`{"world": %q, "aliens": 10%s}`, strings.Join(cities, "\n"), seedField)
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// Function that returns the city each alien of an engine is located in
+func positions(e *engine.Engine) map[int]string {
+	cities := make(map[int]string)
+	for id, al := range e.World.Aliens {
+		cities[id] = al.City.Name
+	}
+	return cities
+}
+
+func TestEngineFromScenario(t *testing.T) {
+	var tests = []struct {
+		name      string
+		seedField string
+		seed      int64
+		wanted    int64
+	}{
+		{"flag seed", "", 7, 7},
+		{"scenario seed", `, "seed": 3`, 7, 3},
+		{"scenario seed only", `, "seed": 3`, 0, 3},
+		{"no seed", "", 0, 0},
+	}
+
+	for _, test := range tests {
+		p := writeScenario(t, test.seedField)
+		first, seed, err := engineFromScenario(p, test.seed)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if seed != test.wanted {
+			t.Errorf("%s: expected seed %d, got %d", test.name, test.wanted, seed)
+		}
+		if test.wanted == 0 {
+			continue
+		}
+
+		// The same seed always deploys the aliens in the same cities
+		second, _, err := engineFromScenario(p, test.seed)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if !reflect.DeepEqual(positions(first), positions(second)) {
+			t.Errorf("%s: expected the same deployment, got %v and %v", test.name, positions(first), positions(second))
+		}
+	}
+}
+
+func TestIgnoredFlags(t *testing.T) {
+	set := flag.NewFlagSet("cli", flag.ContinueOnError)
+	set.Int("n", 0, "")
+	set.Int("m", 0, "")
+	set.Int64("seed", 0, "")
+	set.Bool("analyze", false, "")
+	if err := set.Parse([]string{"-n", "5", "-seed", "3", "-analyze"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ignored := ignoredFlags(set); !reflect.DeepEqual(ignored, []string{"n"}) {
+		t.Errorf("Expected only -n to be ignored, got %v", ignored)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AzraelSec/mad-aliens/pkg/mapgen"
+)
+
+const (
+	DEFAULT_TOPOLOGY = mapgen.GRID
+	DEFAULT_SIZE     = 10
+	DEFAULT_SYMMETRY = 1.0
+	DEFAULT_DENSITY  = 0.5
+	DEFAULT_REWIRING = 0.1
+	DEFAULT_ATTACH   = 2
+	DEFAULT_ISLANDS  = 3
+)
+
+var (
+	o        = flag.String("o", "", "output file to write the world definition to (default stdout)")
+	topology = flag.String("t", DEFAULT_TOPOLOGY, "map topology: "+strings.Join(mapgen.Topologies, ", "))
+	width    = flag.Int("width", DEFAULT_SIZE, "number of columns of grid-based topologies")
+	height   = flag.Int("height", DEFAULT_SIZE, "number of rows of grid-based topologies")
+	cities   = flag.Int("cities", DEFAULT_SIZE*DEFAULT_SIZE, "number of cities of graph-based topologies")
+	symmetry = flag.Float64("symmetry", DEFAULT_SYMMETRY, "probability that a road also has its way back")
+	density  = flag.Float64("density", DEFAULT_DENSITY, "probability of keeping each optional road (planar, islands)")
+	rewiring = flag.Float64("rewiring", DEFAULT_REWIRING, "probability of rewiring a road into a shortcut (smallworld)")
+	attach   = flag.Int("attach", DEFAULT_ATTACH, "number of roads each new city attaches with (scalefree)")
+	islands  = flag.Int("islands", DEFAULT_ISLANDS, "number of islands (islands)")
+	seed     = flag.Int64("seed", 0, "seed of the random generator (default random)")
+)
+
+func init() {
+	flag.Parse()
+}
+
+func main() {
+	opts := mapgen.Options{
+		Width:    *width,
+		Height:   *height,
+		Cities:   *cities,
+		Symmetry: *symmetry,
+		Density:  *density,
+		Rewiring: *rewiring,
+		Attach:   *attach,
+		Islands:  *islands,
+		Seed:     *seed,
+	}
+
+	// The seed is logged, so that an interesting map can be generated again
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	log.Printf("Generating %s map with seed %d", *topology, opts.Seed)
+
+	m, err := mapgen.Generate(*topology, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *o != "" {
+		f, err := os.Create(*o)
+		if err != nil {
+			log.Fatalf("Impossible to create file %s: %s", *o, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if _, err := m.WriteTo(out); err != nil {
+		log.Fatalf("Impossible to write map: %s", err)
+	}
+	log.Printf("%d cities generated", len(m.Cities))
+}
@@ -1,20 +1,100 @@
 package alien
 
-import "github.com/AzraelSec/mad-aliens/pkg/city"
+import (
+	"fmt"
 
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+)
+
+// Default combat attributes of a newly created alien
+const (
+	DEFAULT_HIT_POINTS = 10
+	DEFAULT_STRENGTH   = 5
+)
+
+// Constants that define the kind of an agent
+const (
+	ALIEN    = iota // Invader, fighting other aliens and destroying cities
+	DEFENDER        // Human defender, killing lone aliens and never destroying cities
+)
+
+type Kind int
+
+// Agent living in the world: despite the name, it is either an alien or a defender
 type Alien struct {
 	Id        int        // Aliens identification number (assigned during the world initialization)
-	City      *city.City // Pointer to the city the alien is currently located in
+	City      *city.City // Pointer to the city the alien is currently located in (or has left, if in transit)
+	Transit   *Transit   // Road the alien is traveling along, nil if it is in a city
+	Kind      Kind       // Kind of agent (alien by default)
 	Stuck     bool       // A boolean indicating if alien is stuck or not
 	Destroyed bool       // A boolean indicating if alien is destroyed or not
+
+	Name       string            // Optional alien name
+	Species    string            // Optional alien species
+	Faction    string            // Optional faction the alien belongs to
+	Attributes map[string]string // Optional custom attributes
+
+	HitPoints int // Remaining health: the alien dies when it drops to zero
+	Strength  int // Combat strength, used to roll fight outcomes and to damage opponents
+
+	Path    []Step         // Cities the alien arrived in, in order (possibly only the most recent ones)
+	Visited map[string]int // Number of times the alien arrived in each city
 }
 
 // Function to instanciate a new Alien
+// The starting city, if any, is the first step of its path.
 func NewAlien(id int, city *city.City) *Alien {
-	return &Alien{
+	a := &Alien{
 		Id:        id,
 		City:      city,
 		Stuck:     false,
 		Destroyed: false,
+		HitPoints: DEFAULT_HIT_POINTS,
+		Strength:  DEFAULT_STRENGTH,
+	}
+	if city != nil {
+		a.RecordStep(Step{Round: 0, City: city.Name}, 0)
+	}
+	return a
+}
+
+// Method that returns the alien display name, used in events and reports.
+// The identification number is always included, since names are not guaranteed to be unique.
+func (a *Alien) String() string {
+	kind := "alien"
+	if a.IsDefender() {
+		kind = "defender"
+	}
+	if a.Name == "" {
+		return fmt.Sprintf("%s %d", kind, a.Id)
+	}
+	return fmt.Sprintf("%s (%s %d)", a.Name, kind, a.Id)
+}
+
+// Method that checks whether the agent is a defender
+func (a *Alien) IsDefender() bool {
+	return a.Kind == DEFENDER
+}
+
+// Method that returns a copy of the alien that shares no mutable state with it, besides the cities it points to
+func (a *Alien) Copy() *Alien {
+	c := *a
+	if a.Transit != nil {
+		transit := *a.Transit
+		c.Transit = &transit
+	}
+	if a.Attributes != nil {
+		c.Attributes = make(map[string]string, len(a.Attributes))
+		for k, v := range a.Attributes {
+			c.Attributes[k] = v
+		}
+	}
+	c.Path = append([]Step(nil), a.Path...)
+	if a.Visited != nil {
+		c.Visited = make(map[string]int, len(a.Visited))
+		for k, v := range a.Visited {
+			c.Visited[k] = v
+		}
 	}
+	return &c
 }
@@ -0,0 +1,112 @@
+package alien
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+)
+
+// Identity metadata that can be assigned to an alien
+type Identity struct {
+	Name       string            // Alien name
+	Species    string            // Alien species
+	Faction    string            // Faction the alien belongs to
+	Attributes map[string]string // Custom attributes
+	HitPoints  int               // Initial health (the default one if zero)
+	Strength   int               // Combat strength (the default one if zero)
+}
+
+// Method that assigns an identity to an alien
+func (a *Alien) SetIdentity(id Identity) {
+	a.Name = id.Name
+	a.Species = id.Species
+	a.Faction = id.Faction
+	a.Attributes = make(map[string]string, len(id.Attributes))
+	for k, v := range id.Attributes {
+		a.Attributes[k] = v
+	}
+	if id.HitPoints > 0 {
+		a.HitPoints = id.HitPoints
+	}
+	if id.Strength > 0 {
+		a.Strength = id.Strength
+	}
+}
+
+// Function that parses a list of identities, one per line.
+// Each line contains the alien name, optionally followed by key=value pairs separated by a single space,
+// with the same format used by the world definition (e.g. "Zorg species=martian faction=red color=green").
+// The species, faction, hp and strength keys fill the corresponding fields, while the other ones become custom attributes.
+func ParseIdentities(in io.Reader) ([]Identity, error) {
+	var (
+		scanner    = bufio.NewScanner(in)
+		identities = make([]Identity, 0)
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		items := strings.Split(line, " ")
+		id := Identity{Name: items[0], Attributes: make(map[string]string)}
+		for _, item := range items[1:] {
+			sep := strings.Index(item, "=")
+			if sep <= 0 {
+				return nil, fmt.Errorf("invalid alien attribute: %s", item)
+			}
+
+			key, value := item[:sep], item[sep+1:]
+			switch key {
+			case "species":
+				id.Species = value
+			case "faction":
+				id.Faction = value
+			case "hp", "strength":
+				n, err := strconv.Atoi(value)
+				if err != nil || n <= 0 {
+					return nil, fmt.Errorf("invalid alien %s: %s", key, value)
+				}
+				if key == "hp" {
+					id.HitPoints = n
+				} else {
+					id.Strength = n
+				}
+			default:
+				id.Attributes[key] = value
+			}
+		}
+		identities = append(identities, id)
+	}
+
+	return identities, scanner.Err()
+}
+
+var (
+	consonants = []rune("bcdfghklmnprstvxz")
+	vowels     = []rune("aeiouy")
+)
+
+// Function that generates a random pronounceable name (e.g. "Zorak"),
+// alternating random consonants and vowels
+func RandomName() string {
+	var b strings.Builder
+	syllables := utils.RandomInt(2) + 2
+	for i := 0; i < syllables; i++ {
+		b.WriteString(utils.RandomStringFrom(consonants, 1))
+		b.WriteString(utils.RandomStringFrom(vowels, 1))
+	}
+	if utils.RandomBool() {
+		b.WriteString(utils.RandomStringFrom(consonants, 1))
+	}
+
+	name := []rune(b.String())
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
@@ -0,0 +1,113 @@
+package alien
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestParseIdentities(t *testing.T) {
+	var tests = []struct {
+		input       string
+		parseError  bool
+		wantedNames []string
+	}{
+		// Names with and without attributes, blank lines are skipped
+		{
+			input: `Zorg species=martian faction=red color=green
+
+							Blip`,
+			parseError:  false,
+			wantedNames: []string{"Zorg", "Blip"},
+		},
+		// Attributes must be key=value pairs
+		{
+			input:      "Zorg martian",
+			parseError: true,
+		},
+		// Combat stats must be positive numbers
+		{
+			input:      "Zorg hp=strong",
+			parseError: true,
+		},
+		{
+			input:      "Zorg strength=0",
+			parseError: true,
+		},
+	}
+
+	for _, test := range tests {
+		identities, err := ParseIdentities(strings.NewReader(test.input))
+		if test.parseError {
+			if err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+			continue
+		}
+
+		if len(identities) != len(test.wantedNames) {
+			t.Errorf("Expected %d identities, got %d", len(test.wantedNames), len(identities))
+			continue
+		}
+
+		for i, name := range test.wantedNames {
+			if identities[i].Name != name {
+				t.Errorf("Expected name %s, got %s", name, identities[i].Name)
+			}
+		}
+	}
+
+	identities, _ := ParseIdentities(strings.NewReader("Zorg species=martian faction=red color=green"))
+	a := NewAlien(0, nil)
+	a.SetIdentity(identities[0])
+	if a.Species != "martian" || a.Faction != "red" || a.Attributes["color"] != "green" {
+		t.Errorf("Unexpected identity %+v", a)
+	}
+
+	identities, _ = ParseIdentities(strings.NewReader("Kodos hp=20"))
+	fighter := NewAlien(1, nil)
+	fighter.SetIdentity(identities[0])
+	if fighter.HitPoints != 20 || fighter.Strength != DEFAULT_STRENGTH || len(fighter.Attributes) != 0 {
+		t.Errorf("Unexpected combat stats %+v", fighter)
+	}
+
+	if a.String() != "Zorg (alien 0)" {
+		t.Errorf("Expected Zorg (alien 0), got %s", a)
+	}
+}
+
+func TestRandomName(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		name := RandomName()
+		if len(name) < 4 || !unicode.IsUpper(rune(name[0])) {
+			t.Errorf("Unexpected name %s", name)
+		}
+	}
+
+	if s := NewAlien(7, nil).String(); s != "alien 7" {
+		t.Errorf("Expected alien 7, got %s", s)
+	}
+}
+
+func TestRecordStep(t *testing.T) {
+	a := NewAlien(0, nil)
+	for i, name := range []string{"A", "B", "A", "C"} {
+		a.RecordStep(Step{Round: i, City: name}, 2)
+	}
+
+	if len(a.Path) != 2 || a.Path[0].City != "A" || a.Path[1].City != "C" {
+		t.Errorf("Expected the last 2 steps, got %+v", a.Path)
+	}
+	if previous, exists := a.PreviousCity(); !exists || previous != "A" {
+		t.Errorf("Expected previous city A, got %s", previous)
+	}
+	// Visited cities are not forgotten with the path
+	if a.Coverage() != 3 || a.Visited["A"] != 2 {
+		t.Errorf("Unexpected visited cities %v", a.Visited)
+	}
+}
@@ -0,0 +1,35 @@
+package alien
+
+// Step of the path followed by an alien
+type Step struct {
+	Round     int    `json:"round"`               // Round the alien arrived in the city during
+	City      string `json:"city"`                // Name of the city the alien arrived in
+	Direction string `json:"direction,omitempty"` // Direction of the road taken to get there (empty for the starting city)
+}
+
+// Method that records the arrival of the alien in a new city.
+// If limit is positive, only the last limit steps of the path are kept, while the visited cities are never forgotten.
+func (a *Alien) RecordStep(s Step, limit int) {
+	a.Path = append(a.Path, s)
+	if limit > 0 && len(a.Path) > limit {
+		a.Path = append(a.Path[:0], a.Path[len(a.Path)-limit:]...)
+	}
+
+	if a.Visited == nil {
+		a.Visited = make(map[string]int)
+	}
+	a.Visited[s.City]++
+}
+
+// Method that returns the city the alien arrived from, if it is still in the recorded path
+func (a *Alien) PreviousCity() (string, bool) {
+	if len(a.Path) < 2 {
+		return "", false
+	}
+	return a.Path[len(a.Path)-2].City, true
+}
+
+// Method that returns the number of distinct cities the alien has been in
+func (a *Alien) Coverage() int {
+	return len(a.Visited)
+}
@@ -0,0 +1,24 @@
+package alien
+
+import "github.com/AzraelSec/mad-aliens/pkg/city"
+
+// Road an alien is traveling along, when it takes more than a round to reach the next city
+type Transit struct {
+	From      *city.City // City the alien left
+	To        *city.City // City the alien is traveling to
+	Direction string     // Direction of the road
+	Remaining int        // Number of rounds left before the arrival
+}
+
+// Method that checks whether the alien is traveling between two cities
+func (a *Alien) InTransit() bool {
+	return a.Transit != nil
+}
+
+// Method that returns the city the alien is in or, if it is in transit, the one it is traveling to
+func (a *Alien) NextCity() *city.City {
+	if a.Transit != nil {
+		return a.Transit.To
+	}
+	return a.City
+}
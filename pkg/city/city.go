@@ -3,6 +3,13 @@ package city
 type City struct {
 	Name      string // City identification number (assigned during the world initialization)
 	Destroyed bool   // When a city is destroyed, a soft-deleted is performed
+
+	Population int // Number of inhabitants
+	Defense    int // Amount of damage the city absorbs during each fight
+	HitPoints  int // Remaining structural hit points (cities without any fall to the first damaging fight)
+
+	Damage     int // Total damage the city has taken, after its defense
+	Casualties int // Number of inhabitants killed during the fights
 }
 
 // Function to instanciate a new City
@@ -12,3 +19,27 @@ func NewCity(name string) *City {
 		Destroyed: false,
 	}
 }
+
+// Method that applies the damage of a fight to the city, returning whether the city falls.
+// The defense is subtracted from the damage, and the inhabitants die proportionally to the hit points lost.
+func (c *City) TakeDamage(damage int) bool {
+	damage -= c.Defense
+	if damage <= 0 {
+		return false
+	}
+
+	if c.HitPoints <= 0 || damage >= c.HitPoints {
+		c.Damage += damage
+		c.Casualties += c.Population
+		c.Population = 0
+		c.HitPoints = 0
+		return true
+	}
+
+	killed := c.Population * damage / c.HitPoints
+	c.Damage += damage
+	c.Casualties += killed
+	c.Population -= killed
+	c.HitPoints -= damage
+	return false
+}
@@ -0,0 +1,34 @@
+package city
+
+import "testing"
+
+func TestTakeDamage(t *testing.T) {
+	var tests = []struct {
+		city             City
+		damages          []int
+		wantedDestroyed  bool
+		wantedHitPoints  int
+		wantedCasualties int
+	}{
+		// Cities without hit points fall to the first fight
+		{City{Population: 100}, []int{20}, true, 0, 100},
+		// The defense absorbs part of the damage
+		{City{Population: 100, Defense: 5, HitPoints: 30}, []int{20}, false, 15, 50},
+		{City{Population: 100, Defense: 5, HitPoints: 30}, []int{20, 20}, true, 0, 100},
+		{City{Population: 100, Defense: 20}, []int{20, 20}, false, 0, 0},
+	}
+
+	for _, test := range tests {
+		c, destroyed := test.city, false
+		for _, damage := range test.damages {
+			destroyed = c.TakeDamage(damage)
+		}
+
+		if destroyed != test.wantedDestroyed {
+			t.Errorf("Expected destroyed %t, got %t", test.wantedDestroyed, destroyed)
+		}
+		if c.HitPoints != test.wantedHitPoints || c.Casualties != test.wantedCasualties {
+			t.Errorf("Expected %d hit points and %d casualties, got %+v", test.wantedHitPoints, test.wantedCasualties, c)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+)
+
+// Constants that define the available combat resolvers
+const (
+	MUTUAL_COMBAT        = "mutual"
+	PROBABILISTIC_COMBAT = "probabilistic"
+)
+
+// Available combat resolvers
+var CombatModes = []string{MUTUAL_COMBAT, PROBABILISTIC_COMBAT}
+
+// Outcome of a fight between two aliens
+type CombatOutcome struct {
+	Casualties   []int // Ids of the aliens killed during the fight
+	Damage       int   // Total amount of damage dealt during the fight
+	DestroysCity bool  // A boolean indicating if the city the fight took place in gets destroyed
+}
+
+// Resolver that decides how a fight between two aliens ends.
+// It may change the hit points of the aliens, but it must not destroy them: casualties are handled by the engine.
+type CombatResolver interface {
+	Resolve(a, b *alien.Alien) CombatOutcome
+}
+
+// Resolver where both the aliens and the city are always destroyed
+type MutualDestruction struct{}
+
+// Method that destroys both the aliens and the city
+func (MutualDestruction) Resolve(a, b *alien.Alien) CombatOutcome {
+	return CombatOutcome{
+		Casualties:   []int{a.Id, b.Id},
+		Damage:       a.HitPoints + b.HitPoints,
+		DestroysCity: true,
+	}
+}
+
+// Resolver where the outcome is rolled from the aliens stats.
+// The winner kills the loser and gets a random damage up to the loser strength, dying as well if its hit points drop to zero.
+// The city is destroyed only when the total damage dealt is above the threshold.
+type ProbabilisticCombat struct {
+	CityDamageThreshold int // Damage above which the city gets destroyed
+}
+
+// Function that returns the probability of a to win a fight against b.
+// Each alien wins proportionally to its power, that is its strength times its hit points.
+func WinProbability(a, b *alien.Alien) float64 {
+	pa, pb := power(a), power(b)
+	if pa+pb == 0 {
+		return 0.5
+	}
+	return float64(pa) / float64(pa+pb)
+}
+
+func power(a *alien.Alien) int {
+	if a.Strength <= 0 || a.HitPoints <= 0 {
+		return 0
+	}
+	return a.Strength * a.HitPoints
+}
+
+// Method that rolls the winner of the fight and applies the damage
+func (c ProbabilisticCombat) Resolve(a, b *alien.Alien) CombatOutcome {
+	winner, loser := a, b
+	if utils.RandomFloat() >= WinProbability(a, b) {
+		winner, loser = b, a
+	}
+
+	// The loser is killed, while the winner is hit back with up to the loser strength
+	damage := 0
+	if loser.Strength > 0 {
+		damage = utils.RandomInt(loser.Strength + 1)
+	}
+	damage = minInt(damage, winner.HitPoints)
+	winner.HitPoints -= damage
+
+	outcome := CombatOutcome{
+		Casualties: []int{loser.Id},
+		Damage:     loser.HitPoints + damage,
+	}
+	loser.HitPoints = 0
+	if winner.HitPoints == 0 {
+		outcome.Casualties = append(outcome.Casualties, winner.Id)
+	}
+	outcome.DestroysCity = outcome.Damage > c.CityDamageThreshold
+
+	return outcome
+}
+
+// Function that returns the combat resolver with the given name
+func NewCombatResolver(mode string, cityDamageThreshold int) (CombatResolver, error) {
+	switch mode {
+	case MUTUAL_COMBAT:
+		return MutualDestruction{}, nil
+	case PROBABILISTIC_COMBAT:
+		return ProbabilisticCombat{CityDamageThreshold: cityDamageThreshold}, nil
+	default:
+		return nil, fmt.Errorf("unknown combat mode: %s", mode)
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+func TestWinProbability(t *testing.T) {
+	newAlien := func(hp, strength int) *alien.Alien {
+		a := alien.NewAlien(0, nil)
+		a.HitPoints, a.Strength = hp, strength
+		return a
+	}
+
+	var tests = []struct {
+		a, b   *alien.Alien
+		wanted float64
+	}{
+		{newAlien(10, 5), newAlien(10, 5), 0.5},
+		{newAlien(10, 3), newAlien(10, 1), 0.75},
+		{newAlien(10, 5), newAlien(10, 0), 1},
+		{newAlien(0, 5), newAlien(0, 5), 0.5},
+	}
+
+	for _, test := range tests {
+		if p := WinProbability(test.a, test.b); p != test.wanted {
+			t.Errorf("Expected probability %f, got %f", test.wanted, p)
+		}
+	}
+}
+
+func TestResolveCombat(t *testing.T) {
+	var tests = []struct {
+		resolver       CombatResolver
+		wantedDead     []int
+		wantedDamage   int
+		wantedDestroys bool
+	}{
+		{MutualDestruction{}, []int{0, 1}, 20, true},
+		// Alien 1 has no strength, so alien 0 always wins without getting hurt
+		{ProbabilisticCombat{CityDamageThreshold: 9}, []int{1}, 10, true},
+		{ProbabilisticCombat{CityDamageThreshold: 10}, []int{1}, 10, false},
+	}
+
+	for _, test := range tests {
+		a, b := alien.NewAlien(0, nil), alien.NewAlien(1, nil)
+		b.Strength = 0
+
+		outcome := test.resolver.Resolve(a, b)
+		if len(outcome.Casualties) != len(test.wantedDead) {
+			t.Errorf("Expected casualties %v, got %v", test.wantedDead, outcome.Casualties)
+			continue
+		}
+		for i, id := range test.wantedDead {
+			if outcome.Casualties[i] != id {
+				t.Errorf("Expected casualties %v, got %v", test.wantedDead, outcome.Casualties)
+			}
+		}
+		if outcome.Damage != test.wantedDamage {
+			t.Errorf("Expected damage %d, got %d", test.wantedDamage, outcome.Damage)
+		}
+		if outcome.DestroysCity != test.wantedDestroys {
+			t.Errorf("Expected city destruction %t, got %t", test.wantedDestroys, outcome.DestroysCity)
+		}
+	}
+
+	if _, err := NewCombatResolver("duel", 0); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestWinnerSurvives(t *testing.T) {
+	cityA := city.NewCity("A")
+	a0, a1 := alien.NewAlien(0, cityA), alien.NewAlien(1, cityA)
+	a1.Strength = 0
+
+	e := &Engine{
+		World:   world.NewWorld(world.CityMap{"A": cityA}, world.LinkMap{}, world.AliensMap{0: a0, 1: a1}),
+		MaxRuns: 10,
+		Combat:  ProbabilisticCombat{CityDamageThreshold: 100},
+	}
+
+	report, err := e.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(report.Survivors) != 1 || report.Survivors[0].Id != 0 || report.Survivors[0].HitPoints != alien.DEFAULT_HIT_POINTS {
+		t.Errorf("Unexpected survivors %+v", report.Survivors)
+	}
+	if cityA.Destroyed || len(report.DestroyedCities) != 0 {
+		t.Errorf("Expected city A to survive the fight")
+	}
+}
+
+func TestDefendedCity(t *testing.T) {
+	capital := city.NewCity("A")
+	capital.Population, capital.Defense, capital.HitPoints = 100, 5, 40
+
+	aliens := make(world.AliensMap)
+	for id := 0; id < 4; id++ {
+		aliens[id] = alien.NewAlien(id, capital)
+	}
+	e := &Engine{
+		World:   world.NewWorld(world.CityMap{"A": capital}, world.LinkMap{}, aliens),
+		MaxRuns: 10,
+	}
+
+	report, err := e.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Two fights take place, each dealing 20 damage minus the city defense
+	if capital.Destroyed || len(report.DestroyedCities) != 0 {
+		t.Errorf("Expected the capital to survive")
+	}
+	if len(report.DamagedCities) != 1 {
+		t.Fatalf("Expected 1 damaged city, got %+v", report.DamagedCities)
+	}
+	wanted := CityReport{Name: "A", Damage: 30, Casualties: 74, Population: 26, HitPoints: 10}
+	if report.DamagedCities[0] != wanted || report.Casualties != 74 {
+		t.Errorf("Expected %+v, got %+v", wanted, report.DamagedCities[0])
+	}
+}
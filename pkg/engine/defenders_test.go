@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+func TestDefenders(t *testing.T) {
+	var tests = []struct {
+		aliens, defenders             int
+		wantedAliens, wantedDefenders int // Survivors of each kind
+	}{
+		{1, 1, 0, 1}, // Defenders kill lone aliens
+		{1, 2, 0, 2},
+		{2, 1, 2, 0}, // Two aliens overwhelm a defender
+		{4, 1, 4, 0},
+		{3, 2, 2, 1}, // A pair of aliens overwhelms a defender, the other one kills the lone alien
+		{2, 0, 2, 0}, // Allied aliens coexist as usual
+	}
+
+	for _, test := range tests {
+		cityA := city.NewCity("A")
+		w := world.NewWorld(world.CityMap{"A": cityA}, world.LinkMap{}, world.AliensMap{})
+		for i := 0; i < test.aliens; i++ {
+			al, _ := w.SpawnAlien("A")
+			al.Faction = "red"
+		}
+		for i := 0; i < test.defenders; i++ {
+			if _, err := w.SpawnDefender("A"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		e := &Engine{World: w, MaxRuns: 1}
+		report, err := e.Run()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if report.Stats.AliveAliens != test.wantedAliens || report.Stats.AliveDefenders != test.wantedDefenders {
+			t.Errorf(
+				"%d aliens against %d defenders: expected %d aliens and %d defenders, got %+v",
+				test.aliens, test.defenders, test.wantedAliens, test.wantedDefenders, report.Stats,
+			)
+		}
+		// Defenders never destroy cities
+		if cityA.Destroyed {
+			t.Errorf("%d aliens against %d defenders: expected A to survive", test.aliens, test.defenders)
+		}
+	}
+}
+
+func TestDefendersRelation(t *testing.T) {
+	var (
+		invader  = alien.NewAlien(0, nil)
+		defender = alien.NewAlien(1, nil)
+		comrade  = alien.NewAlien(2, nil)
+	)
+	invader.Faction = "red"
+	defender.Kind, comrade.Kind = alien.DEFENDER, alien.DEFENDER
+
+	d, _ := ParseDiplomacy("red/red=neutral:0.5")
+	if d.Relation(invader, defender).Kind != ENEMIES || d.Relation(defender, comrade).Kind != ALLIES {
+		t.Errorf("Expected defenders to be enemies of the aliens and allies of each other")
+	}
+	if defender.String() != "defender 1" {
+		t.Errorf("Expected defender 1, got %s", defender)
+	}
+}
@@ -1,9 +1,13 @@
 package engine
 
 import (
+	"fmt"
 	"io"
 	"log"
+	"sort"
 
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
 	"github.com/AzraelSec/mad-aliens/pkg/world"
 )
 
@@ -22,14 +26,29 @@ func NewEngine(nAliens int, mRounds int, in io.Reader) (*Engine, error) {
 }
 
 // Method that starts the evaluation loop.
-// The execution ends when all the aliens are dead, all the aliens are stuck or the max number of runs is reached.
-func (e *Engine) Run() (ExecutionStatus, error) {
+// The execution ends when all the aliens are dead, all the aliens are stuck, no aliens can meet anymore
+// or the max number of runs is reached.
+// Once completed, a report summing up the execution is returned.
+func (e *Engine) Run() (*RunReport, error) {
+	// Malformed scripted events are reported in advance, instead of several rounds in
+	for _, ev := range e.Script {
+		if err := ev.Validate(); err != nil {
+			return nil, fmt.Errorf("scripted %s event: %w", ev.Action, err)
+		}
+	}
+	if err := e.Spawn.Validate(e.World); err != nil {
+		return nil, err
+	}
+
 	completed, err := ExecutionStatus(RUNNING), error(nil)
 	for err == nil && completed == RUNNING {
 		log.Printf("======= Run #%d =======\n", e.Runs)
 		completed, err = e.tick()
 	}
-	return completed, err
+	if err != nil {
+		return nil, err
+	}
+	return e.report(completed), nil
 }
 
 // Method that performs a single execution step
@@ -39,24 +58,87 @@ func (e *Engine) tick() (ExecutionStatus, error) {
 		return status, nil
 	}
 
-	// Array that keeps track of the cities that gets visited and the id of the visitor alien
-	visited := make(map[string]int)
+	e.World.StartRound(e.Runs)
+	if err := e.applyScript(); err != nil {
+		return RUNNING, err
+	}
+	if e.RebuildAfter > 0 {
+		for _, name := range e.World.RebuildCities(e.RebuildAfter) {
+			log.Printf("%s has been rebuilt", name)
+			e.rebuilds = append(e.rebuilds, CityRebuild{City: name, Round: e.Runs})
+			e.emit(Event{Kind: CITY_REBUILT, City: name})
+		}
+	}
+	if err := e.spawn(); err != nil {
+		return RUNNING, err
+	}
+
+	// Map that keeps track of the cities that get visited and the ids of the visitor aliens, in order of arrival
+	visited := make(map[string][]int)
+
+	// Aliens act in increasing order of identification number, so that seeded executions are reproducible.
+	// In parallel mode their moves are chosen in advance, and only performed here.
+	ids := e.World.SortedAlienIds()
+	plans, err := e.planMoves(ids)
+	if err != nil {
+		return RUNNING, err
+	}
+	for i, id := range ids {
+		alien := e.World.Aliens[id]
 
-	for _, alien := range e.World.Aliens {
 		// If alien has been destroyed, skip
 		if alien.Destroyed {
 			continue
 		}
 
-		// Identify a random move that each alien will perform
+		// Aliens in transit cannot be attacked, so they only join the city visitors once they arrive
+		if alien.InTransit() {
+			from := alien.City.Name
+			arrived, err := e.World.Travel(alien.Id)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if !arrived {
+				log.Printf("%s is traveling from %s to %s", alien, from, alien.Transit.To.Name)
+				continue
+			}
+			log.Printf("%s arrived in city %s from %s", alien, alien.City.Name, from)
+			e.emit(Event{Kind: ALIEN_MOVED, Aliens: []int{alien.Id}, City: alien.City.Name, From: from})
+			e.visit(visited, alien)
+			continue
+		}
+
+		// Identify the move that each alien will perform: it may wait, or move according to its movement strategy
 		currentCityName := alien.City.Name
-		moved, err := e.World.RandomlyMove(alien.Id)
+		var moved bool
+		switch {
+		case plans != nil && plans[i].wait:
+			_, err = e.World.Wait(alien.Id)
+		case plans != nil:
+			moved, err = e.World.MoveAlong(alien.Id, plans[i].move, &plans[i].rng)
+		case e.isFrozen(currentCityName) || e.WaitProbability > 0 && utils.RandomFloat() < e.WaitProbability:
+			_, err = e.World.Wait(alien.Id)
+		default:
+			moved, err = e.World.Move(alien.Id)
+		}
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		if !moved {
-			log.Printf("Alien %d is in a city %s with no available directions", alien.Id, alien.City.Name)
+		if alien.InTransit() {
+			log.Printf("%s left city %s and is traveling to %s", alien, currentCityName, alien.Transit.To.Name)
+			e.emit(Event{Kind: ALIEN_DEPARTED, Aliens: []int{alien.Id}, City: alien.Transit.To.Name, From: currentCityName})
+			continue
+		} else if moved {
+			log.Printf("%s is in city %s and moving to %s", alien, currentCityName, alien.City.Name)
+			e.emit(Event{Kind: ALIEN_MOVED, Aliens: []int{alien.Id}, City: alien.City.Name, From: currentCityName})
+		} else if !alien.Stuck {
+			// Waiting aliens are still in their city, so they fight the aliens landing there
+			log.Printf("%s waits in city %s", alien, alien.City.Name)
+			e.emit(Event{Kind: ALIEN_WAITED, Aliens: []int{alien.Id}, City: alien.City.Name})
+		} else {
+			log.Printf("%s is in a city %s with no available directions", alien, alien.City.Name)
+			e.emit(Event{Kind: ALIEN_STUCK, Aliens: []int{alien.Id}, City: alien.City.Name})
 
 			/*
 			* Assume that a city has no links (aliens get stuck) and two aliens, A and B, land on it and destroy it.
@@ -66,29 +148,17 @@ func (e *Engine) tick() (ExecutionStatus, error) {
 				if err := e.World.DestroyAliens([]int{alien.Id}); err != nil {
 					log.Fatal(err)
 				}
-
+				e.emit(Event{Kind: ALIEN_KILLED, Aliens: []int{alien.Id}, City: alien.City.Name})
+				continue
 			}
-		} else {
-			log.Printf("Alien %d is in city %s and moving to %s", alien.Id, currentCityName, alien.City.Name)
-		}
-
-		/*
-		* If arrival city has already been visited before during this turn, check if a fight is required
-		*
-		* Note: this implementation assumes that two aliens fight only if they LAND on the same city.
-		* If an alien moves to a city that already has another alien whose move has not been evaluated yet,
-		* no fight is performed.
-		 */
-		if _, exists := visited[alien.City.Name]; exists {
-			if err := e.handleFight(alien.Id, visited[alien.City.Name], alien.City.Name); err != nil {
-				log.Fatal(err)
-			} else {
-				delete(visited, alien.City.Name)
-			}
-		} else {
-			// Else register that visit
-			visited[alien.City.Name] = alien.Id
 		}
+
+		e.visit(visited, alien)
+	}
+
+	e.defend(visited)
+	if e.HeadOnCollisions {
+		e.headOnCollisions()
 	}
 
 	// Increment the runs counter and return the ending condition
@@ -96,14 +166,175 @@ func (e *Engine) tick() (ExecutionStatus, error) {
 	return e.completed(), nil
 }
 
+// Method that registers an alien landing in a city during the current round, making it fight if required
+func (e *Engine) visit(visited map[string][]int, alien *alien.Alien) {
+	/*
+	* If arrival city has already been visited before during this turn, check if a fight is required
+	*
+	* Note: this implementation assumes that two aliens fight only if they LAND on the same city.
+	* If an alien moves to a city that already has another alien whose move has not been evaluated yet,
+	* no fight is performed.
+	* Allied aliens coexist in the same city, so the newcomer fights the first visitor it is hostile to.
+	 */
+	for _, visitor := range visited[alien.City.Name] {
+		// Defenders are handled at the end of the round, once all the agents reached their city
+		if alien.IsDefender() || e.World.Aliens[visitor].IsDefender() {
+			continue
+		}
+		if !e.Diplomacy.Fight(alien, e.World.Aliens[visitor]) {
+			continue
+		}
+
+		if err := e.handleFight(alien.Id, visitor, alien.City.Name); err != nil {
+			log.Fatal(err)
+		}
+		break
+	}
+
+	// Register the visit of the newcomer, if it survived, and forget the visitors that did not
+	if alien.City.Destroyed {
+		delete(visited, alien.City.Name)
+		return
+	}
+	visitors := append(visited[alien.City.Name], alien.Id)
+	visited[alien.City.Name] = visitors[:0]
+	for _, id := range visitors {
+		if !e.World.Aliens[id].Destroyed {
+			visited[alien.City.Name] = append(visited[alien.City.Name], id)
+		}
+	}
+}
+
+// Method that resolves the encounters between aliens and defenders in each city, once all the agents moved.
+// Aliens pair up against the defenders: each pair of aliens overwhelms a defender, while each remaining defender
+// kills a lone alien. Defenders never damage the cities.
+func (e *Engine) defend(visited map[string][]int) {
+	cities := make([]string, 0, len(visited))
+	for name := range visited {
+		cities = append(cities, name)
+	}
+	sort.Strings(cities)
+
+	for _, name := range cities {
+		aliens, defenders := make([]int, 0), make([]int, 0)
+		for _, id := range visited[name] {
+			switch al := e.World.Aliens[id]; {
+			case al.Destroyed:
+				continue
+			case al.IsDefender():
+				defenders = append(defenders, id)
+			default:
+				aliens = append(aliens, id)
+			}
+		}
+		if len(aliens) == 0 || len(defenders) == 0 {
+			continue
+		}
+
+		overwhelmed := minInt(len(defenders), len(aliens)/2)
+		killed := append(make([]int, 0), defenders[:overwhelmed]...)
+		if overwhelmed < len(defenders) && len(aliens)%2 == 1 {
+			// The last alien is alone against the remaining defenders
+			killed = append(killed, aliens[len(aliens)-1])
+		}
+
+		if err := e.World.DestroyAliens(killed); err != nil {
+			log.Fatal(err)
+		}
+		for _, id := range killed {
+			kind := EventKind(ALIEN_KILLED)
+			if e.World.Aliens[id].IsDefender() {
+				kind = DEFENDER_KILLED
+			}
+			log.Printf("%s has been killed in %s", e.World.Aliens[id], name)
+			e.emit(Event{Kind: kind, Aliens: []int{id}, City: name})
+		}
+	}
+}
+
+// Method that makes the hostile aliens traveling the same road in opposite directions fight (defenders never do).
+// Each alien fights at most once per round, and the cities at the ends of the road are never damaged.
+func (e *Engine) headOnCollisions() {
+	// Aliens in transit, grouped by the road they are traveling along
+	travelers := make(map[[2]string][]int)
+	for _, id := range e.World.SortedAlienIds() {
+		if al := e.World.Aliens[id]; !al.Destroyed && !al.IsDefender() && al.InTransit() {
+			road := [2]string{al.City.Name, al.Transit.To.Name}
+			travelers[road] = append(travelers[road], id)
+		}
+	}
+
+	fought := make(map[int]bool)
+	roads := make([][2]string, 0, len(travelers))
+	for road := range travelers {
+		roads = append(roads, road)
+	}
+	sort.Slice(roads, func(i, j int) bool {
+		if roads[i][0] != roads[j][0] {
+			return roads[i][0] < roads[j][0]
+		}
+		return roads[i][1] < roads[j][1]
+	})
+
+	for _, road := range roads {
+		opposite := travelers[[2]string{road[1], road[0]}]
+		for _, a1 := range travelers[road] {
+			for _, a2 := range opposite {
+				if fought[a1] || fought[a2] || !e.Diplomacy.Fight(e.World.Aliens[a1], e.World.Aliens[a2]) {
+					continue
+				}
+				fought[a1], fought[a2] = true, true
+				if err := e.handleCollision(a1, a2, road[0], road[1]); err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+// Method to handle a fight between two aliens colliding on the road between two cities
+func (e *Engine) handleCollision(a1 int, a2 int, from string, to string) error {
+	combat := e.Combat
+	if combat == nil {
+		combat = MutualDestruction{}
+	}
+
+	outcome := combat.Resolve(e.World.Aliens[a1], e.World.Aliens[a2])
+	if err := e.World.DestroyAliens(outcome.Casualties); err != nil {
+		return err
+	}
+
+	log.Printf("%s and %s collided on the road between %s and %s", e.World.Aliens[a1], e.World.Aliens[a2], from, to)
+	e.emit(Event{Kind: HEAD_ON_COLLISION, Aliens: []int{a1, a2}, City: to, From: from})
+	for _, id := range outcome.Casualties {
+		log.Printf("%s has been killed on the road between %s and %s", e.World.Aliens[id], from, to)
+		e.emit(Event{Kind: ALIEN_KILLED, Aliens: []int{id}, City: to, From: from})
+	}
+	return nil
+}
+
 // Method that checks if at least an ending condition is met
 func (e *Engine) completed() ExecutionStatus {
-	aliveAliens, stuckAliens := e.World.CountAliveAliens(), e.World.StuckAliens
-	if aliveAliens == 0 {
+	// While more aliens are about to arrive or scripted events are scheduled, only the max number of runs stops the execution
+	if e.Spawn.Pending(e.Runs) || e.scriptPending() {
+		if e.Runs >= e.MaxRuns {
+			return MAX_ROUND_REACHED
+		}
+		return RUNNING
+	}
+
+	stats := e.World.Stats()
+	if stats.AliveAliens == 0 && !e.Ignore[NO_ALIENS_LEFT] {
 		return NO_ALIENS_LEFT // There are no alive aliens left
 	}
-	if stuckAliens >= aliveAliens {
-		return ALL_ALIENS_STUCK // All alive aliens are stuck
+	// Rebuilt cities may free stuck aliens and reconnect separated ones
+	if e.RebuildAfter == 0 || len(e.World.RebuildPending()) == 0 {
+		if stats.StuckAliens == stats.AliveAliens && stats.AliveDefenders == 0 && !e.Ignore[ALL_ALIENS_STUCK] {
+			return ALL_ALIENS_STUCK // All alive aliens are stuck, and no defender can reach them
+		}
+		if !e.Ignore[NO_POSSIBLE_FIGHTS] && !e.fightsPossible() {
+			return NO_POSSIBLE_FIGHTS // Next executions would only move aliens around
+		}
 	}
 	if e.Runs >= e.MaxRuns {
 		return MAX_ROUND_REACHED // Max number of runs reached
@@ -112,17 +343,49 @@ func (e *Engine) completed() ExecutionStatus {
 }
 
 // Method to handle a fight between two aliens that land on the same city.
+// The outcome is decided by the combat resolver: the casualties are destroyed and the city is damaged, if required.
 func (e *Engine) handleFight(a1 int, a2 int, city string) error {
-	// Destroy both aliens that fought
-	if err := e.World.DestroyAliens([]int{a1, a2}); err != nil {
+	combat := e.Combat
+	if combat == nil {
+		combat = MutualDestruction{}
+	}
+
+	outcome := combat.Resolve(e.World.Aliens[a1], e.World.Aliens[a2])
+	if err := e.World.DestroyAliens(outcome.Casualties); err != nil {
 		return err
 	}
+	for _, id := range outcome.Casualties {
+		e.emit(Event{Kind: ALIEN_KILLED, Aliens: []int{id}, City: city})
+	}
+
+	if !outcome.DestroysCity {
+		for _, id := range outcome.Casualties {
+			log.Printf("%s has been killed in %s", e.World.Aliens[id], city)
+		}
+		return nil
+	}
 
-	// Destroy the involved city
-	if err := e.World.DestroyCities([]string{city}); err != nil {
+	// Damage the involved city, which falls unless its defenses hold
+	destroyed, err := e.World.DamageCity(city, outcome.Damage)
+	if err != nil {
 		return err
 	}
+	if !destroyed {
+		c := e.World.Cities[city]
+		log.Printf("%s resisted the fight between %s and %s (%d hit points left)", city, e.World.Aliens[a1], e.World.Aliens[a2], c.HitPoints)
+		e.emit(Event{Kind: CITY_DAMAGED, Aliens: []int{a1, a2}, City: city})
+		return nil
+	}
+
+	ids := []int{a1, a2}
+	sort.Ints(ids)
+	destruction := CityDestruction{City: city, Round: e.Runs, Aliens: ids, Names: make([]string, len(ids))}
+	for i, id := range ids {
+		destruction.Names[i] = e.World.Aliens[id].String()
+	}
+	e.destructions = append(e.destructions, destruction)
+	e.emit(Event{Kind: CITY_DESTROYED, Aliens: ids, City: city})
 
-	log.Printf("%s has been destroyed by alien %d and alien %d!", city, a1, a2)
+	log.Printf("%s has been destroyed by %s and %s!", city, e.World.Aliens[a1], e.World.Aliens[a2])
 	return nil
 }
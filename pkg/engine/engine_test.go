@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/AzraelSec/mad-aliens/pkg/alien"
@@ -71,7 +73,12 @@ func TestCompleted(t *testing.T) {
 		cityA = city.NewCity("A")
 		cityB = city.NewCity("B")
 		cityC = city.NewCity("C")
+		cityD = city.NewCity("D")
+		cityE = city.NewCity("E")
+		cityF = city.NewCity("F")
+		cityG = city.NewCity("G")
 		a1    = alien.NewAlien(0, cityA)
+		a2    = alien.NewAlien(1, cityB)
 	)
 
 	var tests = []struct {
@@ -79,13 +86,18 @@ func TestCompleted(t *testing.T) {
 		expectError bool
 		expectValue ExecutionStatus
 	}{
-		// All aliens are stuck
+		// All alive aliens are stuck: the alien moving from E fights with the one stuck in D,
+		// while the one in F gets stuck
 		{
 			engineItem: &Engine{
 				World: world.NewWorld(
-					world.CityMap{"A": cityA},
-					world.LinkMap{},
-					world.AliensMap{0: a1},
+					world.CityMap{"D": cityD, "E": cityE, "F": cityF},
+					world.LinkMap{"E": map[world.Direction]*city.City{world.West: cityD}},
+					world.AliensMap{
+						0: alien.NewAlien(0, cityD),
+						1: alien.NewAlien(1, cityE),
+						2: alien.NewAlien(2, cityF),
+					},
 				),
 				MaxRuns: 1,
 				Runs:    0,
@@ -107,13 +119,30 @@ func TestCompleted(t *testing.T) {
 			expectError: false,
 			expectValue: NO_ALIENS_LEFT,
 		},
-		// Max round reached
+		// No alive aliens can meet: the one in F cannot reach G and vice versa
+		{
+			engineItem: &Engine{
+				World: world.NewWorld(
+					world.CityMap{"F": cityF, "G": cityG},
+					world.LinkMap{},
+					world.AliensMap{0: alien.NewAlien(0, cityF), 1: alien.NewAlien(1, cityG)},
+				),
+				MaxRuns: 1,
+				Runs:    0,
+			},
+			expectError: false,
+			expectValue: NO_POSSIBLE_FIGHTS,
+		},
+		// Max round reached: the two aliens swap their cities
 		{
 			engineItem: &Engine{
 				World: world.NewWorld(
 					world.CityMap{"A": cityA, "B": cityB},
-					world.LinkMap{"A": map[world.Direction]*city.City{world.North: cityB}},
-					world.AliensMap{0: a1},
+					world.LinkMap{
+						"A": map[world.Direction]*city.City{world.North: cityB},
+						"B": map[world.Direction]*city.City{world.South: cityA},
+					},
+					world.AliensMap{0: a1, 1: a2},
 				),
 				MaxRuns: 1,
 				Runs:    0,
@@ -121,7 +150,7 @@ func TestCompleted(t *testing.T) {
 			expectError: false,
 			expectValue: MAX_ROUND_REACHED,
 		},
-		// Still running
+		// Still running: the alien moving to B can still reach the one moving to C
 		{
 			engineItem: &Engine{
 				World: world.NewWorld(
@@ -130,7 +159,7 @@ func TestCompleted(t *testing.T) {
 						"A": map[world.Direction]*city.City{world.North: cityB},
 						"B": map[world.Direction]*city.City{world.South: cityC},
 					},
-					world.AliensMap{0: a1},
+					world.AliensMap{0: a1, 1: a2},
 				),
 				MaxRuns: 2,
 				Runs:    0,
@@ -205,3 +234,33 @@ func TestHandleFight(t *testing.T) {
 		}
 	}
 }
+
+func TestForkedRuns(t *testing.T) {
+	base, err := world.Parse(strings.NewReader("Foo north=Bar west=Baz south=Qu-ux\nBar south=Foo west=Bee\nQu-ux north=Foo east=Bar\nBee east=Bar"), 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := base.Stats()
+
+	// Parallel experiments on the same parsed map do not affect each other, nor the map
+	var wg sync.WaitGroup
+	for _, w := range []*world.World{base.Fork(), base.Fork(), base.Clone()} {
+		wg.Add(1)
+		go func(w *world.World) {
+			defer wg.Done()
+			if _, err := (&Engine{World: w, MaxRuns: 100}).Run(); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if base.Stats() != before || base.Round != 0 {
+		t.Errorf("Expected the base world to be untouched, got %+v", base.Stats())
+	}
+	for _, al := range base.Aliens {
+		if len(al.Path) != 1 || al.Destroyed {
+			t.Errorf("Expected alien %d to be untouched, got %+v", al.Id, al)
+		}
+	}
+}
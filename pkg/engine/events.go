@@ -0,0 +1,40 @@
+package engine
+
+// Constants that define the kind of the events emitted during the execution
+const (
+	ALIEN_MOVED       = iota + 1 // An alien moved into a new city
+	ALIEN_WAITED                 // An alien chose to stay in its city for the round
+	ALIEN_STUCK                  // An alien could not move, since its city has no available roads
+	ALIEN_KILLED                 // An alien died
+	CITY_DESTROYED               // A city has been destroyed during a fight
+	ALIEN_DEPARTED               // An alien left its city along a road that takes more than a round
+	HEAD_ON_COLLISION            // Two aliens traveling the same road in opposite directions fought
+	CITY_DAMAGED                 // A city has been damaged during a fight, but its defenses held
+	CITY_REBUILT                 // A destroyed city has been rebuilt
+	ALIEN_SPAWNED                // A new alien arrived in a city
+	DEFENDER_KILLED              // A defender has been overwhelmed by the aliens
+	ROAD_CLOSED                  // A scripted event closed a road leaving the city
+	ROAD_OPENED                  // A scripted event opened a road toward the city
+	ALIEN_TELEPORTED             // A scripted event teleported an alien into the city
+	STORM_STARTED                // A scripted event froze the aliens in the city
+	EVENT_SKIPPED                // A scripted event has been skipped, since the world changed so that it does not apply
+)
+
+type EventKind int
+
+// Event that happened during the execution
+type Event struct {
+	Kind   EventKind // Kind of the event
+	Round  int       // Round the event happened during
+	Aliens []int     // Ids of the involved aliens
+	City   string    // City the event happened in (the destination, for aliens in transit)
+	From   string    // City the alien left, only set for moves and collisions
+}
+
+// Method that notifies an event to the engine handler, if any
+func (e *Engine) emit(ev Event) {
+	if e.OnEvent != nil {
+		ev.Round = e.Runs
+		e.OnEvent(ev)
+	}
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+func TestWaitingAliensFight(t *testing.T) {
+	var (
+		cityA = city.NewCity("A")
+		cityB = city.NewCity("B")
+	)
+
+	events := make([]Event, 0)
+	e := &Engine{
+		World: world.NewWorld(
+			world.CityMap{"A": cityA, "B": cityB},
+			world.LinkMap{"A": map[world.Direction]*city.City{world.North: cityB}},
+			world.AliensMap{0: alien.NewAlien(0, cityA), 1: alien.NewAlien(1, cityA)},
+		),
+		MaxRuns:         10,
+		WaitProbability: 1,
+		OnEvent:         func(ev Event) { events = append(events, ev) },
+	}
+
+	report, err := e.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Both aliens wait in A, where they meet and destroy the city
+	if report.Status != NO_ALIENS_LEFT || !cityA.Destroyed {
+		t.Errorf("Expected both aliens to fight in A, got status %d", report.Status)
+	}
+
+	wanted := []EventKind{ALIEN_WAITED, ALIEN_WAITED, ALIEN_KILLED, ALIEN_KILLED, CITY_DESTROYED}
+	if len(events) != len(wanted) {
+		t.Fatalf("Expected %d events, got %+v", len(wanted), events)
+	}
+	for i, kind := range wanted {
+		if events[i].Kind != kind || events[i].City != "A" || events[i].Round != 0 {
+			t.Errorf("Expected %s in A at round 0, got %s in %s at round %d", EventKindString(kind), EventKindString(events[i].Kind), events[i].City, events[i].Round)
+		}
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	var (
+		cityB = city.NewCity("B")
+		cityC = city.NewCity("C")
+	)
+
+	rebuilt := make([]Event, 0)
+	e := &Engine{
+		World: world.NewWorld(
+			world.CityMap{"B": cityB, "C": cityC},
+			world.LinkMap{},
+			world.AliensMap{0: alien.NewAlien(0, cityC), 1: alien.NewAlien(1, cityC), 2: alien.NewAlien(2, cityB)},
+		),
+		MaxRuns:      10,
+		RebuildAfter: 2,
+		OnEvent: func(ev Event) {
+			if ev.Kind == CITY_REBUILT {
+				rebuilt = append(rebuilt, ev)
+			}
+		},
+	}
+
+	report, err := e.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The run goes on while C is waiting to be rebuilt, even if the only survivor is stuck
+	if report.Status != ALL_ALIENS_STUCK || report.Rounds != 3 {
+		t.Errorf("Expected all aliens stuck after 3 rounds, got status %d after %d rounds", report.Status, report.Rounds)
+	}
+	if cityC.Destroyed || len(report.RebuiltCities) != 1 || report.RebuiltCities[0] != (CityRebuild{City: "C", Round: 2}) {
+		t.Errorf("Expected C to be rebuilt at round 2, got %+v", report.RebuiltCities)
+	}
+	if len(rebuilt) != 1 || rebuilt[0].City != "C" || rebuilt[0].Round != 2 {
+		t.Errorf("Expected a CityRebuilt event, got %+v", rebuilt)
+	}
+}
@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+)
+
+// Constants that define the relationship between two factions
+const (
+	ENEMIES = iota + 1 // Aliens always fight
+	ALLIES             // Aliens coexist peacefully in the same city
+	NEUTRAL            // Aliens fight with a given probability
+)
+
+type RelationKind int
+
+// Relationship between two factions
+type Relation struct {
+	Kind        RelationKind // Kind of relationship
+	Probability float64      // Probability of fighting, only used by neutral relationships
+}
+
+// Unordered pair of factions
+type factionPair [2]string
+
+func newFactionPair(a, b string) factionPair {
+	if a > b {
+		a, b = b, a
+	}
+	return factionPair{a, b}
+}
+
+// Relationship matrix between factions.
+// Unless specified otherwise, aliens of the same faction are allies and any other pair of aliens are enemies.
+// Aliens without a faction are enemies of everyone, so that the original behavior is preserved.
+type Diplomacy struct {
+	relations map[factionPair]Relation
+}
+
+// Function to instanciate a new Diplomacy with the default rules only
+func NewDiplomacy() *Diplomacy {
+	return &Diplomacy{relations: make(map[factionPair]Relation)}
+}
+
+// Method that sets the relationship between two factions, in both directions
+func (d *Diplomacy) Set(a, b string, r Relation) {
+	d.relations[newFactionPair(a, b)] = r
+}
+
+// Method that returns the relationship between two aliens
+// Defenders are enemies of all the aliens and allies of each other, regardless of the factions.
+func (d *Diplomacy) Relation(a, b *alien.Alien) Relation {
+	if a.IsDefender() || b.IsDefender() {
+		if a.Kind == b.Kind {
+			return Relation{Kind: ALLIES}
+		}
+		return Relation{Kind: ENEMIES}
+	}
+	if a.Faction == "" || b.Faction == "" {
+		return Relation{Kind: ENEMIES}
+	}
+	if d != nil {
+		if r, exists := d.relations[newFactionPair(a.Faction, b.Faction)]; exists {
+			return r
+		}
+	}
+	if a.Faction == b.Faction {
+		return Relation{Kind: ALLIES}
+	}
+	return Relation{Kind: ENEMIES}
+}
+
+// Method that checks whether two aliens may ever fight
+func (d *Diplomacy) Hostile(a, b *alien.Alien) bool {
+	r := d.Relation(a, b)
+	return r.Kind == ENEMIES || (r.Kind == NEUTRAL && r.Probability > 0)
+}
+
+// Method that decides whether two aliens landing in the same city fight, rolling the dice for neutral ones
+func (d *Diplomacy) Fight(a, b *alien.Alien) bool {
+	switch r := d.Relation(a, b); r.Kind {
+	case ENEMIES:
+		return true
+	case NEUTRAL:
+		return utils.RandomFloat() < r.Probability
+	default:
+		return false
+	}
+}
+
+// Function that parses a comma separated list of relationships between factions.
+// Each relationship has the format faction/faction=kind, where kind is enemies, allies or neutral:probability
+// (e.g. "red/blue=neutral:0.3,red/green=allies").
+func ParseDiplomacy(s string) (*Diplomacy, error) {
+	d := NewDiplomacy()
+	if strings.TrimSpace(s) == "" {
+		return d, nil
+	}
+
+	for _, item := range strings.Split(s, ",") {
+		sep := strings.Index(item, "=")
+		if sep == -1 {
+			return nil, fmt.Errorf("invalid relationship: %s", item)
+		}
+
+		factions := strings.Split(strings.TrimSpace(item[:sep]), "/")
+		if len(factions) != 2 || factions[0] == "" || factions[1] == "" {
+			return nil, fmt.Errorf("invalid faction pair: %s", item[:sep])
+		}
+
+		r, err := parseRelation(strings.TrimSpace(item[sep+1:]))
+		if err != nil {
+			return nil, err
+		}
+		d.Set(factions[0], factions[1], r)
+	}
+
+	return d, nil
+}
+
+func parseRelation(s string) (Relation, error) {
+	kind, probability, hasProbability := s, "", false
+	if sep := strings.Index(s, ":"); sep != -1 {
+		kind, probability, hasProbability = s[:sep], s[sep+1:], true
+	}
+
+	switch kind {
+	case "enemies":
+		return Relation{Kind: ENEMIES}, nil
+	case "allies":
+		return Relation{Kind: ALLIES}, nil
+	case "neutral":
+		if !hasProbability {
+			return Relation{}, fmt.Errorf("missing fight probability: %s", s)
+		}
+		p, err := strconv.ParseFloat(probability, 64)
+		if err != nil || p < 0 || p > 1 {
+			return Relation{}, fmt.Errorf("invalid fight probability: %s", probability)
+		}
+		return Relation{Kind: NEUTRAL, Probability: p}, nil
+	default:
+		return Relation{}, fmt.Errorf("invalid relationship kind: %s", kind)
+	}
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+func TestParseDiplomacy(t *testing.T) {
+	var tests = []struct {
+		input       string
+		expectError bool
+	}{
+		{"", false},
+		{"red/blue=neutral:0.3,red/green=allies,blue/green=enemies", false},
+		{"red/blue", true},
+		{"red=allies", true},
+		{"red/blue=friends", true},
+		{"red/blue=neutral", true},
+		{"red/blue=neutral:2", true},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseDiplomacy(test.input); (err != nil) != test.expectError {
+			t.Errorf("%s: expected error %t, got %v", test.input, test.expectError, err)
+		}
+	}
+}
+
+func TestRelation(t *testing.T) {
+	d, err := ParseDiplomacy("red/blue=neutral:0.3,green/red=allies,blue/blue=enemies")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newAlien := func(faction string) *alien.Alien {
+		a := alien.NewAlien(0, nil)
+		a.Faction = faction
+		return a
+	}
+
+	var tests = []struct {
+		a, b     string
+		kind     RelationKind
+		hostile  bool
+		fallback RelationKind // Relation with the default rules
+	}{
+		{"red", "red", ALLIES, false, ALLIES},
+		{"blue", "red", NEUTRAL, true, ENEMIES},
+		{"red", "green", ALLIES, false, ENEMIES},
+		{"blue", "blue", ENEMIES, true, ALLIES},
+		{"blue", "green", ENEMIES, true, ENEMIES},
+		{"", "", ENEMIES, true, ENEMIES},
+		{"red", "", ENEMIES, true, ENEMIES},
+	}
+
+	for _, test := range tests {
+		a, b := newAlien(test.a), newAlien(test.b)
+		if r := d.Relation(a, b); r.Kind != test.kind {
+			t.Errorf("%s/%s: expected %d, got %d", test.a, test.b, test.kind, r.Kind)
+		}
+		if h := d.Hostile(a, b); h != test.hostile {
+			t.Errorf("%s/%s: expected hostile %t, got %t", test.a, test.b, test.hostile, h)
+		}
+
+		// Without a relationship matrix, the default rules are used
+		var defaults *Diplomacy
+		if r := defaults.Relation(a, b); r.Kind != test.fallback {
+			t.Errorf("%s/%s: expected default %d, got %d", test.a, test.b, test.fallback, r.Kind)
+		}
+	}
+}
+
+func TestAlliesCoexist(t *testing.T) {
+	var (
+		cityA = city.NewCity("A")
+		cityB = city.NewCity("B")
+		cityC = city.NewCity("C")
+		red1  = alien.NewAlien(0, cityA)
+		red2  = alien.NewAlien(1, cityC)
+		blue  = alien.NewAlien(2, cityB)
+	)
+	red1.Faction, red2.Faction, blue.Faction = "red", "red", "blue"
+
+	// Both red aliens land in B, where the blue one is stuck
+	e := &Engine{
+		World: world.NewWorld(
+			world.CityMap{"A": cityA, "B": cityB, "C": cityC},
+			world.LinkMap{
+				"A": map[world.Direction]*city.City{world.East: cityB},
+				"C": map[world.Direction]*city.City{world.West: cityB},
+			},
+			world.AliensMap{red1.Id: red1, red2.Id: red2, blue.Id: blue},
+		),
+		MaxRuns: 10,
+	}
+
+	report, err := e.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The blue alien fights the first red one, the other red alien survives
+	if report.Stats.AliveAliens != 1 || report.Stats.DestroyedAliens != 2 {
+		t.Errorf("Unexpected stats %+v", report.Stats)
+	}
+	if report.Winner != "red" {
+		t.Errorf("Expected red to win, got %s", report.Winner)
+	}
+	if len(report.Factions) != 2 || report.Factions[0] != (FactionReport{"blue", 0, 1}) || report.Factions[1] != (FactionReport{"red", 1, 1}) {
+		t.Errorf("Unexpected factions %+v", report.Factions)
+	}
+
+	// Two allies alone never fight, so the run stops immediately
+	red3 := alien.NewAlien(3, cityB)
+	red3.Faction = "red"
+	e.World.Aliens[red3.Id] = red3
+	cityB.Destroyed = false
+	red1.Destroyed, red2.Destroyed, blue.Destroyed = false, false, true
+	red1.City, red2.City = cityB, cityB
+
+	if status, _ := e.tick(); status != NO_POSSIBLE_FIGHTS {
+		t.Errorf("Expected %d, got %d", NO_POSSIBLE_FIGHTS, status)
+	}
+}
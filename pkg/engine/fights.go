@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"github.com/AzraelSec/mad-aliens/pkg/world/analysis"
+)
+
+// Outcome of the last check of the possible fights.
+// Moving within a strongly connected component, an alien can still reach the same cities: the outcome only changes
+// when the topology of the world changes, or an alien spawns, dies or moves to another component.
+type fightsCheck struct {
+	topology   uint64            // Topology version of the world the components belong to
+	components map[string]string // Component of each surviving city, identified by its first city
+	regions    map[int]string    // Component (or destroyed city) each alive alien was heading to during the check
+	possible   bool              // Whether any two hostile aliens could meet
+}
+
+// Method that checks whether any two hostile aliens can still meet, running the analysis again only when its
+// outcome may have changed since the last check
+func (e *Engine) fightsPossible() bool {
+	c := e.fights
+	if c == nil || c.topology != e.World.Topology() {
+		c = &fightsCheck{topology: e.World.Topology(), components: make(map[string]string)}
+		for _, component := range analysis.StronglyConnectedComponents(e.World) {
+			for _, name := range component {
+				c.components[name] = component[0]
+			}
+		}
+		e.fights = c
+	} else if c.regions != nil && c.sameRegions(e) {
+		return c.possible
+	}
+
+	c.regions = make(map[int]string)
+	for id, al := range e.World.Aliens {
+		if !al.Destroyed && al.NextCity() != nil {
+			c.regions[id] = c.region(al.NextCity().Name)
+		}
+	}
+	c.possible = analysis.FightsPossible(e.World, e.Diplomacy.Hostile)
+	return c.possible
+}
+
+// Method that returns the component a city belongs to or, since they are in none, the destroyed city itself
+func (c *fightsCheck) region(name string) string {
+	if component, exists := c.components[name]; exists {
+		return component
+	}
+	return name
+}
+
+// Method that checks whether the alive aliens are the same as during the last check, each in the same region
+func (c *fightsCheck) sameRegions(e *Engine) bool {
+	alive := 0
+	for id, al := range e.World.Aliens {
+		if al.Destroyed || al.NextCity() == nil {
+			continue
+		}
+		if region, exists := c.regions[id]; !exists || region != c.region(al.NextCity().Name) {
+			return false
+		}
+		alive++
+	}
+	return alive == len(c.regions)
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/mapgen"
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+	"github.com/AzraelSec/mad-aliens/pkg/world/analysis"
+)
+
+func TestFightsCheck(t *testing.T) {
+	// A, B and C are a component aliens can move around, while D cannot be reached
+	w, err := world.Parse(strings.NewReader("A east=B\nB west=A east=C\nC west=B\nD"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.TeleportAlien(0, "A")
+	w.TeleportAlien(1, "C")
+	e := &Engine{World: w}
+
+	if !e.fightsPossible() {
+		t.Fatal("Expected fights to be possible")
+	}
+
+	// Moving within the component, the previous outcome is still valid
+	e.fights.possible = false
+	w.TeleportAlien(0, "B")
+	if e.fightsPossible() {
+		t.Error("Expected the previous outcome to be reused")
+	}
+
+	// Moving to another component, the aliens cannot meet anymore
+	e.fights.possible = true
+	w.TeleportAlien(0, "D")
+	if e.fightsPossible() {
+		t.Error("Expected fights not to be possible")
+	}
+
+	// Opening a road changes the topology
+	if err := w.AddRoad("D", world.North, "A", world.DefaultRoad()); err != nil {
+		t.Fatal(err)
+	}
+	if !e.fightsPossible() {
+		t.Error("Expected fights to be possible again")
+	}
+}
+
+func TestFightsCheckConsistency(t *testing.T) {
+	m, err := mapgen.Planar(mapgen.Options{Width: 8, Height: 8, Density: 0.3, Symmetry: 0.4, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for seed := int64(0); seed < 10; seed++ {
+		utils.Seed(seed)
+		w, err := m.World(12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for id, al := range w.Aliens {
+			al.Faction = []string{"red", "blue", "green"}[id%3]
+		}
+		diplomacy := NewDiplomacy()
+		diplomacy.Set("red", "blue", Relation{Kind: ALLIES})
+		e := &Engine{World: w, MaxRuns: 100, RebuildAfter: 4, Diplomacy: diplomacy}
+
+		// Every round, the check agrees with a fresh analysis
+		for status := ExecutionStatus(RUNNING); status == RUNNING; {
+			if got, wanted := e.fightsPossible(), analysis.FightsPossible(w, e.Diplomacy.Hostile); got != wanted {
+				t.Fatalf("seed %d, round %d: expected fights possible %t, got %t", seed, e.Runs, wanted, got)
+			}
+			if status, err = e.tick(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
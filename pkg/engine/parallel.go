@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Move of an alien chosen at the beginning of the round, in parallel mode
+type plan struct {
+	wait bool             // A boolean indicating if the alien stays in its city for the round
+	move *world.Move      // Move the alien follows, if it does not wait (nil if it stays put)
+	rng  utils.SplitMix64 // Random generator of the alien for the round
+}
+
+// Method that chooses the moves of the aliens in parallel, against the world as it is at the beginning of the round.
+// Aliens are split into contiguous chunks, one per worker, and each of them draws from its own random generator,
+// seeded with a value drawn once per round from the shared one and its id: the chosen moves, and so the whole
+// execution, do not depend on the number of workers.
+// It returns the moves indexed as the given aliens, or nil in sequential mode.
+func (e *Engine) planMoves(ids []int) ([]plan, error) {
+	if e.Workers <= 0 {
+		return nil, nil
+	}
+
+	seed := utils.RandomUint64()
+	plans := make([]plan, len(ids))
+	errs := make([]error, e.Workers)
+	chunk := (len(ids) + e.Workers - 1) / e.Workers
+
+	var wg sync.WaitGroup
+	for worker := 0; worker*chunk < len(ids); worker++ {
+		wg.Add(1)
+		go func(worker int, from int, to int) {
+			defer wg.Done()
+			for i := from; i < to; i++ {
+				if errs[worker] = e.planMove(ids[i], seed, &plans[i]); errs[worker] != nil {
+					return
+				}
+			}
+		}(worker, worker*chunk, minInt((worker+1)*chunk, len(ids)))
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return plans, nil
+}
+
+// Method that chooses the move of a single alien, without changing the world
+func (e *Engine) planMove(id int, seed uint64, p *plan) error {
+	alien := e.World.Aliens[id]
+	if alien.Destroyed || alien.InTransit() {
+		return nil
+	}
+
+	p.rng = utils.NewSplitMix64(seed, uint64(id))
+	if e.isFrozen(alien.City.Name) || e.WaitProbability > 0 && p.rng.Float64() < e.WaitProbability {
+		p.wait = true
+		return nil
+	}
+	move, err := e.World.ChooseMove(id, &p.rng)
+	p.move = move
+	return err
+}
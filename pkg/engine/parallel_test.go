@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/mapgen"
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Function that describes the outcome of an execution: the destroyed and rebuilt cities, in order, and the state of each alien
+func outcome(e *Engine, r *RunReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d rounds, status %d\n", r.Rounds, r.Status)
+	for _, d := range e.destructions {
+		fmt.Fprintf(&b, "%s destroyed at %d by %v\n", d.City, d.Round, d.Aliens)
+	}
+	for _, rebuild := range e.rebuilds {
+		fmt.Fprintf(&b, "%s rebuilt at %d\n", rebuild.City, rebuild.Round)
+	}
+	for _, id := range e.World.SortedAlienIds() {
+		al := e.World.Aliens[id]
+		fmt.Fprintf(&b, "%d: %s destroyed=%t stuck=%t path=%v\n", id, al.City.Name, al.Destroyed, al.Stuck, al.Path)
+	}
+	return b.String()
+}
+
+func TestParallelDeterminism(t *testing.T) {
+	m, err := mapgen.Planar(mapgen.Options{Width: 12, Height: 12, Density: 0.8, Symmetry: 0.7, Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name  string
+		setup func(e *Engine)
+	}{
+		{"uniform", func(e *Engine) {}},
+		{"strategies", func(e *Engine) {
+			e.World.DefaultStrategy = world.LazyMovement{Probability: 0.3, Then: world.ExplorerMovement{}}
+			for id := range e.World.Aliens {
+				if id%3 == 0 {
+					e.World.SetStrategy(id, world.HunterMovement{})
+				}
+			}
+			e.WaitProbability = 0.1
+		}},
+		{"roads and rebuilds", func(e *Engine) {
+			// The northbound roads are slow and narrow
+			for _, name := range e.World.SortedCityNames() {
+				if to, exists := e.World.Links[name][world.North]; exists {
+					if err := e.World.RemoveRoad(name, world.North); err != nil {
+						t.Fatal(err)
+					}
+					if err := e.World.AddRoad(name, world.North, to.Name, world.Road{Weight: 2, Time: 2, Capacity: 1}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			e.HeadOnCollisions = true
+			e.RebuildAfter = 3
+		}},
+	}
+
+	for _, test := range tests {
+		var expected string
+		for _, workers := range []int{1, 2, 3, 8, 64} {
+			utils.Seed(11)
+			w, err := m.World(60)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := &Engine{World: w, MaxRuns: 200, Workers: workers}
+			test.setup(e)
+
+			r, err := e.Run()
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", test.name, err)
+			}
+			if len(e.destructions) == 0 {
+				t.Errorf("%s: expected some cities to be destroyed", test.name)
+			}
+
+			got := outcome(e, r)
+			if expected == "" {
+				expected = got
+			} else if got != expected {
+				t.Errorf("%s: expected the same execution with %d workers, got:\n%s\ninstead of:\n%s", test.name, workers, got, expected)
+			}
+		}
+	}
+}
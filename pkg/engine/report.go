@@ -0,0 +1,440 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Constants that define the available report formats
+const (
+	TEXT_REPORT     = "text"
+	JSON_REPORT     = "json"
+	MARKDOWN_REPORT = "markdown"
+)
+
+// Available report formats
+var ReportFormats = []string{TEXT_REPORT, JSON_REPORT, MARKDOWN_REPORT}
+
+// Record of a city destroyed during a fight or by a scripted event
+type CityDestruction struct {
+	City   string   `json:"city"`   // Name of the destroyed city
+	Round  int      `json:"round"`  // Round the city has been destroyed during
+	Aliens []int    `json:"aliens"` // Ids of the aliens that destroyed the city (empty for scripted events)
+	Names  []string `json:"names"`  // Display names of the aliens that destroyed the city
+}
+
+// Record of a destroyed city that has been rebuilt
+type CityRebuild struct {
+	City  string `json:"city"`  // Name of the rebuilt city
+	Round int    `json:"round"` // Round the city has been rebuilt at the beginning of
+}
+
+// Final state of a city damaged during the invasion
+type CityReport struct {
+	Name       string `json:"name"`       // City name
+	Damage     int    `json:"damage"`     // Total damage taken, after the city defense
+	Casualties int    `json:"casualties"` // Number of inhabitants killed
+	Population int    `json:"population"` // Number of surviving inhabitants
+	HitPoints  int    `json:"hit_points"` // Remaining structural hit points
+	Destroyed  bool   `json:"destroyed"`  // A boolean indicating if the city has been destroyed
+}
+
+// Final state of an alien that survived the invasion
+type SurvivorReport struct {
+	Id         int               `json:"id"`                   // Alien identification number
+	Name       string            `json:"name,omitempty"`       // Alien name
+	Species    string            `json:"species,omitempty"`    // Alien species
+	Faction    string            `json:"faction,omitempty"`    // Faction the alien belongs to
+	Attributes map[string]string `json:"attributes,omitempty"` // Custom attributes
+	City       string            `json:"city"`                 // City the alien is located in at the end of the run
+	Stuck      bool              `json:"stuck"`                // A boolean indicating if the alien is stuck
+	HitPoints  int               `json:"hit_points"`           // Remaining health of the alien
+	TravelTo   string            `json:"travel_to,omitempty"`  // City the alien is traveling to, if it is in transit
+	Defender   bool              `json:"defender,omitempty"`   // A boolean indicating if the survivor is a defender
+}
+
+// Method that returns the survivor display name, consistent with the one used in events
+func (s SurvivorReport) String() string {
+	a := &alien.Alien{Id: s.Id, Name: s.Name}
+	if s.Defender {
+		a.Kind = alien.DEFENDER
+	}
+	return a.String()
+}
+
+// Path followed by an alien during the invasion
+type TrajectoryReport struct {
+	Id        int          `json:"id"`        // Alien identification number
+	Name      string       `json:"name"`      // Alien display name
+	Destroyed bool         `json:"destroyed"` // A boolean indicating if the alien has been killed
+	Coverage  int          `json:"coverage"`  // Number of distinct cities the alien has been in
+	Path      []alien.Step `json:"path"`      // Recorded steps, possibly only the most recent ones
+}
+
+// Outcome of the invasion for a faction
+type FactionReport struct {
+	Faction   string `json:"faction"`   // Faction name
+	Survivors int    `json:"survivors"` // Number of alive aliens of the faction
+	Destroyed int    `json:"destroyed"` // Number of aliens of the faction killed during fights
+}
+
+// Summary of an engine execution
+type RunReport struct {
+	Status          ExecutionStatus    `json:"status"`                 // Termination status
+	Rounds          int                `json:"rounds"`                 // Number of rounds executed
+	Stats           world.Stats        `json:"stats"`                  // Final world statistics
+	Survivors       []SurvivorReport   `json:"survivors"`              // Alive aliens, sorted by id
+	StuckAliens     []int              `json:"stuck_aliens"`           // Ids of the alive aliens that are stuck
+	DestroyedCities []CityDestruction  `json:"destroyed_cities"`       // Destroyed cities, in the order they were destroyed
+	DamagedCities   []CityReport       `json:"damaged_cities"`         // Cities that took damage, sorted by name
+	RebuiltCities   []CityRebuild      `json:"rebuilt_cities"`         // Rebuilt cities, in the order they were rebuilt
+	Casualties      int                `json:"casualties"`             // Total number of inhabitants killed
+	FinalMap        string             `json:"final_map"`              // What is left of the world, in the definition format
+	Factions        []FactionReport    `json:"factions,omitempty"`     // Outcome of each faction, sorted by name
+	Winner          string             `json:"winner,omitempty"`       // Faction with the most survivors, if any
+	VisitedCities   int                `json:"visited_cities"`         // Number of cities visited by at least one alien
+	TotalCities     int                `json:"total_cities"`           // Number of cities of the world
+	Trajectories    []TrajectoryReport `json:"trajectories,omitempty"` // Paths followed by all the aliens, sorted by id, if requested
+}
+
+// Method that serializes the execution status with its description
+func (s ExecutionStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ExecStatusString(s))
+}
+
+// Method that builds the report of the current engine state
+func (e *Engine) report(status ExecutionStatus) *RunReport {
+	r := &RunReport{
+		Status:          status,
+		Rounds:          e.Runs,
+		Stats:           e.World.Stats(),
+		Survivors:       make([]SurvivorReport, 0),
+		StuckAliens:     make([]int, 0),
+		DestroyedCities: append(make([]CityDestruction, 0, len(e.destructions)), e.destructions...),
+		RebuiltCities:   append(make([]CityRebuild, 0, len(e.rebuilds)), e.rebuilds...),
+		FinalMap:        e.World.String(),
+	}
+
+	for _, al := range e.World.Aliens {
+		if al.Destroyed {
+			continue
+		}
+		r.Survivors = append(r.Survivors, SurvivorReport{
+			Id:         al.Id,
+			Name:       al.Name,
+			Species:    al.Species,
+			Faction:    al.Faction,
+			Attributes: al.Attributes,
+			City:       al.City.Name,
+			Stuck:      al.Stuck,
+			HitPoints:  al.HitPoints,
+			Defender:   al.IsDefender(),
+		})
+		if al.InTransit() {
+			r.Survivors[len(r.Survivors)-1].TravelTo = al.Transit.To.Name
+		}
+		if al.Stuck && !al.IsDefender() {
+			r.StuckAliens = append(r.StuckAliens, al.Id)
+		}
+	}
+	sort.Slice(r.Survivors, func(i, j int) bool { return r.Survivors[i].Id < r.Survivors[j].Id })
+	sort.Ints(r.StuckAliens)
+
+	r.Factions, r.Winner = e.factionsOutcome()
+	r.VisitedCities, r.TotalCities = e.World.Coverage()
+
+	r.DamagedCities = make([]CityReport, 0)
+	for _, c := range e.World.Cities {
+		r.Casualties += c.Casualties
+		if c.Damage == 0 && c.Casualties == 0 {
+			continue
+		}
+		r.DamagedCities = append(r.DamagedCities, CityReport{
+			Name:       c.Name,
+			Damage:     c.Damage,
+			Casualties: c.Casualties,
+			Population: c.Population,
+			HitPoints:  c.HitPoints,
+			Destroyed:  c.Destroyed,
+		})
+	}
+	sort.Slice(r.DamagedCities, func(i, j int) bool { return r.DamagedCities[i].Name < r.DamagedCities[j].Name })
+
+	if !e.Trajectories {
+		return r
+	}
+	r.Trajectories = make([]TrajectoryReport, 0, len(e.World.Aliens))
+	for _, id := range e.World.SortedAlienIds() {
+		al := e.World.Aliens[id]
+		r.Trajectories = append(r.Trajectories, TrajectoryReport{
+			Id:        al.Id,
+			Name:      al.String(),
+			Destroyed: al.Destroyed,
+			Coverage:  al.Coverage(),
+			Path:      append(make([]alien.Step, 0, len(al.Path)), al.Path...),
+		})
+	}
+	return r
+}
+
+// Method that computes the outcome of each faction.
+// The winner is the faction with the most survivors; no faction wins in case of a tie.
+func (e *Engine) factionsOutcome() ([]FactionReport, string) {
+	outcomes := make(map[string]*FactionReport)
+	for _, al := range e.World.Aliens {
+		if al.Faction == "" {
+			continue
+		}
+		if outcomes[al.Faction] == nil {
+			outcomes[al.Faction] = &FactionReport{Faction: al.Faction}
+		}
+		if al.Destroyed {
+			outcomes[al.Faction].Destroyed++
+		} else {
+			outcomes[al.Faction].Survivors++
+		}
+	}
+
+	factions := make([]FactionReport, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		factions = append(factions, *outcome)
+	}
+	sort.Slice(factions, func(i, j int) bool { return factions[i].Faction < factions[j].Faction })
+
+	winner, best, tie := "", 0, false
+	for _, f := range factions {
+		switch {
+		case f.Survivors > best:
+			winner, best, tie = f.Faction, f.Survivors, false
+		case f.Survivors == best && best > 0:
+			tie = true
+		}
+	}
+	if tie {
+		winner = ""
+	}
+
+	return factions, winner
+}
+
+// Function that describes the winner of the invasion
+func formatWinner(winner string) string {
+	if winner == "" {
+		return "none"
+	}
+	return winner
+}
+
+// Method that renders the report in the given format
+func (r *RunReport) Render(format string) (string, error) {
+	switch format {
+	case TEXT_REPORT:
+		return r.Text(), nil
+	case JSON_REPORT:
+		return r.JSON()
+	case MARKDOWN_REPORT:
+		return r.Markdown(), nil
+	default:
+		return "", fmt.Errorf("unknown report format: %s", format)
+	}
+}
+
+// Method that renders the report as human readable text
+func (r *RunReport) Text() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Execution completed: %s\n", ExecStatusString(r.Status))
+	fmt.Fprintf(&b, "Rounds executed: %d\n", r.Rounds)
+	fmt.Fprintf(&b, "Cities visited: %d of %d\n", r.VisitedCities, r.TotalCities)
+	fmt.Fprintf(
+		&b,
+		"| %d survived aliens | %d stuck aliens | %d destroyed aliens | %d surviving cities | %d destroyed cities |\n",
+		r.Stats.AliveAliens,
+		r.Stats.StuckAliens,
+		r.Stats.DestroyedAliens,
+		r.Stats.SurvivingCities,
+		r.Stats.DestroyedCities,
+	)
+	if r.Stats.AliveDefenders+r.Stats.DestroyedDefenders > 0 {
+		fmt.Fprintf(&b, "| %d survived defenders | %d destroyed defenders |\n", r.Stats.AliveDefenders, r.Stats.DestroyedDefenders)
+	}
+
+	b.WriteString("\nSurvivors:\n")
+	if len(r.Survivors) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, s := range r.Survivors {
+		stuck := ""
+		if s.Stuck {
+			stuck = " (stuck)"
+		}
+		if s.TravelTo != "" {
+			stuck = " (traveling to " + s.TravelTo + ")"
+		}
+		fmt.Fprintf(&b, "  %s in %s%s%s\n", s, s.City, formatIdentity(s), stuck)
+	}
+
+	if len(r.Factions) > 0 {
+		b.WriteString("\nFactions:\n")
+		for _, f := range r.Factions {
+			fmt.Fprintf(&b, "  %s: %d survivors, %d destroyed\n", f.Faction, f.Survivors, f.Destroyed)
+		}
+		fmt.Fprintf(&b, "  winner: %s\n", formatWinner(r.Winner))
+	}
+
+	b.WriteString("\nDestroyed cities:\n")
+	if len(r.DestroyedCities) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, d := range r.DestroyedCities {
+		fmt.Fprintf(&b, "  %s at round %d by %s\n", d.City, d.Round, formatDestroyers(d, " and "))
+	}
+
+	if len(r.RebuiltCities) > 0 {
+		b.WriteString("\nRebuilt cities:\n")
+		for _, c := range r.RebuiltCities {
+			fmt.Fprintf(&b, "  %s at round %d\n", c.City, c.Round)
+		}
+	}
+
+	if len(r.DamagedCities) > 0 {
+		fmt.Fprintf(&b, "\nDamaged cities (%d casualties):\n", r.Casualties)
+		for _, c := range r.DamagedCities {
+			fmt.Fprintf(&b, "  %s: %d damage, %d casualties, %s\n", c.Name, c.Damage, c.Casualties, formatCityState(c))
+		}
+	}
+
+	b.WriteString("\nFinal map:\n")
+	b.WriteString(r.FinalMap)
+
+	return b.String()
+}
+
+// Method that renders the report as indented JSON
+func (r *RunReport) JSON() (string, error) {
+	out, err := json.MarshalIndent(r, "", "  ")
+	return string(out), err
+}
+
+// Method that renders the report as a Markdown document
+func (r *RunReport) Markdown() string {
+	var b strings.Builder
+
+	b.WriteString("# Invasion report\n\n")
+	fmt.Fprintf(&b, "**Status**: %s  \n", ExecStatusString(r.Status))
+	fmt.Fprintf(&b, "**Rounds executed**: %d  \n", r.Rounds)
+	fmt.Fprintf(&b, "**Cities visited**: %d of %d\n\n", r.VisitedCities, r.TotalCities)
+
+	b.WriteString("| Survived aliens | Stuck aliens | Destroyed aliens | Surviving cities | Destroyed cities |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	fmt.Fprintf(
+		&b,
+		"| %d | %d | %d | %d | %d |\n\n",
+		r.Stats.AliveAliens,
+		r.Stats.StuckAliens,
+		r.Stats.DestroyedAliens,
+		r.Stats.SurvivingCities,
+		r.Stats.DestroyedCities,
+	)
+	if r.Stats.AliveDefenders+r.Stats.DestroyedDefenders > 0 {
+		fmt.Fprintf(&b, "| Survived defenders | Destroyed defenders |\n|---|---|\n| %d | %d |\n\n", r.Stats.AliveDefenders, r.Stats.DestroyedDefenders)
+	}
+
+	b.WriteString("## Survivors\n\n")
+	if len(r.Survivors) == 0 {
+		b.WriteString("None.\n\n")
+	} else {
+		b.WriteString("| Alien | Species | Faction | City | Stuck |\n|---|---|---|---|---|\n")
+		for _, s := range r.Survivors {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %t |\n", s, s.Species, s.Faction, s.City, s.Stuck)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Factions) > 0 {
+		b.WriteString("## Factions\n\n| Faction | Survivors | Destroyed |\n|---|---|---|\n")
+		for _, f := range r.Factions {
+			fmt.Fprintf(&b, "| %s | %d | %d |\n", f.Faction, f.Survivors, f.Destroyed)
+		}
+		fmt.Fprintf(&b, "\n**Winner**: %s\n\n", formatWinner(r.Winner))
+	}
+
+	b.WriteString("## Destroyed cities\n\n")
+	if len(r.DestroyedCities) == 0 {
+		b.WriteString("None.\n\n")
+	} else {
+		b.WriteString("| City | Round | Aliens |\n|---|---|---|\n")
+		for _, d := range r.DestroyedCities {
+			fmt.Fprintf(&b, "| %s | %d | %s |\n", d.City, d.Round, formatDestroyers(d, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.RebuiltCities) > 0 {
+		b.WriteString("## Rebuilt cities\n\n| City | Round |\n|---|---|\n")
+		for _, c := range r.RebuiltCities {
+			fmt.Fprintf(&b, "| %s | %d |\n", c.City, c.Round)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.DamagedCities) > 0 {
+		fmt.Fprintf(&b, "## Damaged cities\n\n**Casualties**: %d\n\n| City | Damage | Casualties | State |\n|---|---|---|---|\n", r.Casualties)
+		for _, c := range r.DamagedCities {
+			fmt.Fprintf(&b, "| %s | %d | %d | %s |\n", c.Name, c.Damage, c.Casualties, formatCityState(c))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Final map\n\n```\n")
+	b.WriteString(r.FinalMap)
+	b.WriteString("\n```\n")
+
+	return b.String()
+}
+
+// Function that formats the aliens that destroyed a city
+func formatDestroyers(d CityDestruction, sep string) string {
+	if len(d.Names) == 0 {
+		return "a scripted event"
+	}
+	return strings.Join(d.Names, sep)
+}
+
+// Function that describes whether a damaged city is still standing
+func formatCityState(c CityReport) string {
+	if c.Destroyed {
+		return "destroyed"
+	}
+	return fmt.Sprintf("%d hit points left", c.HitPoints)
+}
+
+// Function that formats the species, faction and custom attributes of a survivor, if any
+func formatIdentity(s SurvivorReport) string {
+	items := make([]string, 0)
+	if s.Species != "" {
+		items = append(items, "species="+s.Species)
+	}
+	if s.Faction != "" {
+		items = append(items, "faction="+s.Faction)
+	}
+
+	keys := make([]string, 0, len(s.Attributes))
+	for k := range s.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		items = append(items, k+"="+s.Attributes[k])
+	}
+
+	if len(items) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(items, " ") + "]"
+}
@@ -0,0 +1,215 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Function that builds an engine whose run is deterministic:
+// aliens 0 and 1 are stuck in A and destroy it, while alien 2 is stuck in C
+func reportEngine() *Engine {
+	var (
+		cityA = city.NewCity("A")
+		cityB = city.NewCity("B")
+		cityC = city.NewCity("C")
+	)
+
+	return &Engine{
+		World: world.NewWorld(
+			world.CityMap{"A": cityA, "B": cityB, "C": cityC},
+			world.LinkMap{"B": map[world.Direction]*city.City{world.East: cityC}},
+			world.AliensMap{
+				0: alien.NewAlien(0, cityA),
+				1: alien.NewAlien(1, cityA),
+				2: alien.NewAlien(2, cityC),
+			},
+		),
+		MaxRuns: 10,
+	}
+}
+
+func TestRunReport(t *testing.T) {
+	e := reportEngine()
+	e.Trajectories = true
+	report, err := e.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.Status != ALL_ALIENS_STUCK {
+		t.Errorf("Expected status %d, got %d", ALL_ALIENS_STUCK, report.Status)
+	}
+
+	if report.Rounds != 1 {
+		t.Errorf("Expected 1 round, got %d", report.Rounds)
+	}
+
+	if len(report.Survivors) != 1 || report.Survivors[0].Id != 2 || report.Survivors[0].City != "C" || !report.Survivors[0].Stuck {
+		t.Errorf("Unexpected survivors %+v", report.Survivors)
+	}
+
+	if len(report.DestroyedCities) != 1 {
+		t.Fatalf("Expected 1 destroyed city, got %d", len(report.DestroyedCities))
+	}
+	if d := report.DestroyedCities[0]; d.City != "A" || d.Round != 0 || d.Aliens[0] != 0 || d.Aliens[1] != 1 {
+		t.Errorf("Unexpected destruction %+v", d)
+	}
+
+	if report.FinalMap != "B east=C" {
+		t.Errorf("Expected final map B east=C, got %s", report.FinalMap)
+	}
+
+	if report.VisitedCities != 2 || report.TotalCities != 3 {
+		t.Errorf("Expected 2 of 3 visited cities, got %d of %d", report.VisitedCities, report.TotalCities)
+	}
+	if len(report.Trajectories) != 3 {
+		t.Fatalf("Expected 3 trajectories, got %d", len(report.Trajectories))
+	}
+	if tr := report.Trajectories[0]; !tr.Destroyed || tr.Coverage != 1 || len(tr.Path) != 1 || tr.Path[0].City != "A" {
+		t.Errorf("Unexpected trajectory %+v", tr)
+	}
+}
+
+func TestTrajectories(t *testing.T) {
+	var (
+		cityA = city.NewCity("A")
+		cityB = city.NewCity("B")
+		cityC = city.NewCity("C")
+		mover = alien.NewAlien(0, cityA)
+	)
+
+	e := &Engine{
+		World: world.NewWorld(
+			world.CityMap{"A": cityA, "B": cityB, "C": cityC},
+			world.LinkMap{
+				"A": map[world.Direction]*city.City{world.North: cityB},
+				"B": map[world.Direction]*city.City{world.South: cityA},
+				"C": map[world.Direction]*city.City{world.West: cityA},
+			},
+			world.AliensMap{0: mover, 1: alien.NewAlien(1, cityC)},
+		),
+		MaxRuns:      4,
+		Trajectories: true,
+	}
+	// Alien 1 keeps still in C, which alien 0 never reaches, so that the run lasts all the rounds
+	e.World.SetStrategy(1, world.LazyMovement{Probability: 1})
+	e.World.PathLimit = 3
+
+	report, err := e.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	path := report.Trajectories[0].Path
+	if len(path) != 3 {
+		t.Fatalf("Expected 3 steps, got %+v", path)
+	}
+	for i, wanted := range []alien.Step{
+		{Round: 1, City: "A", Direction: "south"},
+		{Round: 2, City: "B", Direction: "north"},
+		{Round: 3, City: "A", Direction: "south"},
+	} {
+		if path[i] != wanted {
+			t.Errorf("Expected step %+v, got %+v", wanted, path[i])
+		}
+	}
+	if report.Trajectories[0].Coverage != 2 || report.Trajectories[1].Coverage != 1 {
+		t.Errorf("Unexpected coverage %+v", report.Trajectories)
+	}
+}
+
+func TestTrajectoriesOmitted(t *testing.T) {
+	report, err := reportEngine().Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Trajectories != nil {
+		t.Errorf("Expected no trajectories unless requested, got %+v", report.Trajectories)
+	}
+
+	out, err := report.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "trajectories") {
+		t.Errorf("Expected the JSON report to omit the trajectories, got %s", out)
+	}
+}
+
+func TestRenderReport(t *testing.T) {
+	report, err := reportEngine().Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var tests = []struct {
+		format      string
+		expectError bool
+		contains    []string
+	}{
+		{TEXT_REPORT, false, []string{"All alive aliens are stuck", "Cities visited: 2 of 3", "alien 2 in C (stuck)", "A at round 0 by alien 0 and alien 1", "B east=C"}},
+		{MARKDOWN_REPORT, false, []string{"# Invasion report", "| alien 2 |  |  | C | true |", "| A | 0 | alien 0, alien 1 |"}},
+		{JSON_REPORT, false, []string{`"status": "All alive aliens are stuck"`, `"final_map": "B east=C"`}},
+		{"xml", true, nil},
+	}
+
+	for _, test := range tests {
+		out, err := report.Render(test.format)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+			continue
+		}
+
+		for _, wanted := range test.contains {
+			if !strings.Contains(out, wanted) {
+				t.Errorf("Expected %s report to contain %s, got:\n%s", test.format, wanted, out)
+			}
+		}
+	}
+
+	// The JSON report can be parsed back by downstream tools
+	out, _ := report.JSON()
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Errorf("Expected valid JSON, got %v", err)
+	}
+	if parsed["rounds"] != float64(1) {
+		t.Errorf("Expected 1 round, got %v", parsed["rounds"])
+	}
+}
+
+func TestReportIdentities(t *testing.T) {
+	e := reportEngine()
+	e.World.AssignIdentities([]alien.Identity{
+		{Name: "Zorg"},
+		{Name: "Blip"},
+		{Name: "Kodos", Species: "rigellian", Faction: "red", Attributes: map[string]string{"color": "green"}},
+	}, false)
+
+	report, err := e.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	text := report.Text()
+	for _, wanted := range []string{
+		"A at round 0 by Zorg (alien 0) and Blip (alien 1)",
+		"Kodos (alien 2) in C [species=rigellian faction=red color=green] (stuck)",
+	} {
+		if !strings.Contains(text, wanted) {
+			t.Errorf("Expected report to contain %s, got:\n%s", wanted, text)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Constants that define the actions of the scripted events
+const (
+	CLOSE_ROAD     = "close_road" // Closes the road leaving City in Direction
+	OPEN_ROAD      = "open_road"  // Opens a road leaving City in Direction toward To, with the Road attributes
+	NUKE_CITIES    = "nuke"       // Destroys the Cities, killing the aliens in them
+	TELEPORT_ALIEN = "teleport"   // Moves the Aliens into City
+	STORM          = "storm"      // Freezes the aliens in the Cities for a number of Rounds
+)
+
+// Available scripted event actions
+var ScriptActions = []string{CLOSE_ROAD, OPEN_ROAD, NUKE_CITIES, TELEPORT_ALIEN, STORM}
+
+// Conditions that trigger a scripted event: all the set ones must hold
+type Condition struct {
+	Round         int    `json:"round,omitempty"`          // Round the event happens at the beginning of, at the earliest
+	AliensBelow   int    `json:"aliens_below,omitempty"`   // Number of alive aliens the event waits to go below
+	CityDestroyed string `json:"city_destroyed,omitempty"` // City whose destruction the event waits for
+}
+
+// Event of the world scripted in advance, applied at the beginning of the first round its conditions hold
+type ScriptedEvent struct {
+	Action string    `json:"action"` // Action performed by the event
+	When   Condition `json:"when"`   // Conditions that trigger the event (the beginning of the execution if empty)
+
+	City      string   `json:"city,omitempty"`      // City the road leaves, or the aliens get teleported to
+	Direction string   `json:"direction,omitempty"` // Direction of the road (e.g. "north")
+	To        string   `json:"to,omitempty"`        // City the opened road leads to
+	Road      string   `json:"road,omitempty"`      // Attributes of the opened road (e.g. "w=3,t=2"), the default ones if empty
+	Cities    []string `json:"cities,omitempty"`    // Cities nuked or hit by the storm
+	Aliens    []int    `json:"aliens,omitempty"`    // Aliens teleported
+	Rounds    int      `json:"rounds,omitempty"`    // Duration of the storm
+
+	fired bool // A boolean indicating if the event already happened
+}
+
+// Method that checks that the event is well formed: its action is known and the fields it requires are valid.
+// Whether its cities and aliens exist can only be checked against the world, once it happens.
+func (ev *ScriptedEvent) Validate() error {
+	switch ev.Action {
+	case CLOSE_ROAD, OPEN_ROAD:
+		if ev.City == "" {
+			return fmt.Errorf("missing city")
+		}
+		if _, ok := world.ParseDirection(ev.Direction); !ok {
+			return fmt.Errorf("invalid direction: %s", ev.Direction)
+		}
+		if ev.Action == CLOSE_ROAD {
+			return nil
+		}
+		if ev.To == "" {
+			return fmt.Errorf("missing destination city")
+		}
+		if ev.Road != "" {
+			if _, err := world.ParseRoad(ev.Road); err != nil {
+				return err
+			}
+		}
+	case NUKE_CITIES:
+		if len(ev.Cities) == 0 {
+			return fmt.Errorf("missing cities")
+		}
+	case TELEPORT_ALIEN:
+		if ev.City == "" {
+			return fmt.Errorf("missing city")
+		}
+		if len(ev.Aliens) == 0 {
+			return fmt.Errorf("missing aliens")
+		}
+	case STORM:
+		if len(ev.Cities) == 0 {
+			return fmt.Errorf("missing cities")
+		}
+		if ev.Rounds < 1 {
+			return fmt.Errorf("invalid storm duration: %d", ev.Rounds)
+		}
+	default:
+		return fmt.Errorf("unknown action: %s", ev.Action)
+	}
+	return nil
+}
+
+// Method that checks whether the conditions of the event hold
+func (c Condition) holds(e *Engine) bool {
+	if e.Runs < c.Round {
+		return false
+	}
+	if c.AliensBelow > 0 && e.World.Stats().AliveAliens >= c.AliensBelow {
+		return false
+	}
+	if c.CityDestroyed != "" {
+		if city, exists := e.World.Cities[c.CityDestroyed]; !exists || !city.Destroyed {
+			return false
+		}
+	}
+	return true
+}
+
+// Method that checks whether scripted events are still scheduled from the current round on.
+// Events waiting for a condition other than the round do not keep the execution going.
+func (e *Engine) scriptPending() bool {
+	for _, ev := range e.Script {
+		if !ev.fired && ev.When.Round >= e.Runs {
+			return true
+		}
+	}
+	return false
+}
+
+// Method that applies the scripted events whose conditions hold, in the order they are scripted.
+// Events the execution made pointless are skipped, while the malformed ones stop it.
+func (e *Engine) applyScript() error {
+	for _, ev := range e.Script {
+		if ev.fired || !ev.When.holds(e) {
+			continue
+		}
+		ev.fired = true
+		if err := e.applyEvent(ev); err != nil && !e.skipped(ev, err, ev.Aliens) {
+			return fmt.Errorf("scripted %s event: %w", ev.Action, err)
+		}
+	}
+	return nil
+}
+
+// Method that checks whether an error only means that the world changed so that the event does not apply anymore
+// (e.g. the alien to teleport died in a fight, or the road to close has already been closed).
+// In that case the event is skipped, and the execution goes on.
+func (e *Engine) skipped(ev *ScriptedEvent, err error, aliens []int) bool {
+	var (
+		alienDestroyed *world.AlienDestroyedError
+		cityDestroyed  *world.CityDestroyedError
+		roadExists     *world.RoadExistsError
+		roadNotFound   *world.RoadNotFoundError
+	)
+	if !errors.As(err, &alienDestroyed) && !errors.As(err, &cityDestroyed) && !errors.As(err, &roadExists) && !errors.As(err, &roadNotFound) {
+		return false
+	}
+	log.Printf("Scripted %s event skipped: %s", ev.Action, err)
+	e.emit(Event{Kind: EVENT_SKIPPED, Aliens: aliens, City: ev.City})
+	return true
+}
+
+// Method that applies a single scripted event to the world
+func (e *Engine) applyEvent(ev *ScriptedEvent) error {
+	switch ev.Action {
+	case CLOSE_ROAD, OPEN_ROAD:
+		d, ok := world.ParseDirection(ev.Direction)
+		if !ok {
+			return fmt.Errorf("invalid direction: %s", ev.Direction)
+		}
+		if ev.Action == CLOSE_ROAD {
+			if err := e.World.RemoveRoad(ev.City, d); err != nil {
+				return err
+			}
+			log.Printf("The road leaving %s toward %s has been closed", ev.City, ev.Direction)
+			e.emit(Event{Kind: ROAD_CLOSED, City: ev.City})
+			return nil
+		}
+
+		road := world.DefaultRoad()
+		if ev.Road != "" {
+			var err error
+			if road, err = world.ParseRoad(ev.Road); err != nil {
+				return err
+			}
+		}
+		if err := e.World.AddRoad(ev.City, d, ev.To, road); err != nil {
+			return err
+		}
+		log.Printf("A road leaving %s toward %s has been opened to %s", ev.City, ev.Direction, ev.To)
+		e.emit(Event{Kind: ROAD_OPENED, City: ev.To, From: ev.City})
+	case NUKE_CITIES:
+		for _, name := range ev.Cities {
+			if err := e.nuke(name); err != nil {
+				return err
+			}
+		}
+	case TELEPORT_ALIEN:
+		for _, id := range ev.Aliens {
+			if err := e.World.TeleportAlien(id, ev.City); err != nil {
+				// The other aliens still get teleported
+				if e.skipped(ev, err, []int{id}) {
+					continue
+				}
+				return err
+			}
+			log.Printf("%s has been teleported to %s", e.World.Aliens[id], ev.City)
+			e.emit(Event{Kind: ALIEN_TELEPORTED, Aliens: []int{id}, City: ev.City})
+		}
+	case STORM:
+		if ev.Rounds < 1 {
+			return fmt.Errorf("invalid storm duration: %d", ev.Rounds)
+		}
+		if e.frozen == nil {
+			e.frozen = make(map[string]int)
+		}
+		for _, name := range ev.Cities {
+			if _, exists := e.World.Cities[name]; !exists {
+				return &world.CityNotFoundError{Name: name}
+			}
+			e.frozen[name] = maxInt(e.frozen[name], e.Runs+ev.Rounds-1)
+			log.Printf("A storm freezes %s for %d rounds", name, ev.Rounds)
+			e.emit(Event{Kind: STORM_STARTED, City: name})
+		}
+	default:
+		return fmt.Errorf("unknown action: %s", ev.Action)
+	}
+	return nil
+}
+
+// Method that destroys a city along with the aliens in it, while the ones traveling toward it are spared
+func (e *Engine) nuke(name string) error {
+	c, exists := e.World.Cities[name]
+	if !exists {
+		return &world.CityNotFoundError{Name: name}
+	}
+	if c.Destroyed {
+		return nil
+	}
+
+	killed := make([]int, 0)
+	for _, id := range e.World.SortedAlienIds() {
+		if al := e.World.Aliens[id]; !al.Destroyed && !al.InTransit() && al.City == c {
+			killed = append(killed, id)
+		}
+	}
+	if err := e.World.DestroyCities([]string{name}); err != nil {
+		return err
+	}
+	if err := e.World.DestroyAliens(killed); err != nil {
+		return err
+	}
+
+	e.destructions = append(e.destructions, CityDestruction{City: name, Round: e.Runs, Aliens: []int{}, Names: []string{}})
+	log.Printf("%s has been nuked!", name)
+	e.emit(Event{Kind: CITY_DESTROYED, City: name})
+	for _, id := range killed {
+		kind := EventKind(ALIEN_KILLED)
+		if e.World.Aliens[id].IsDefender() {
+			kind = DEFENDER_KILLED
+		}
+		e.emit(Event{Kind: kind, Aliens: []int{id}, City: name})
+	}
+	return nil
+}
+
+// Method that checks whether a city is frozen by a storm during the current round
+func (e *Engine) isFrozen(name string) bool {
+	until, exists := e.frozen[name]
+	return exists && e.Runs <= until
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,193 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+func TestScript(t *testing.T) {
+	var tests = []struct {
+		name         string
+		links        map[string]map[world.Direction]string
+		aliens       map[int]string // City each alien starts in
+		script       []*ScriptedEvent
+		maxRuns      int
+		wantedStatus ExecutionStatus
+		wantedRounds int
+		wantedCities map[int]string // City each surviving alien ends in
+		wantedEvent  EventKind
+	}{
+		{
+			"close road",
+			map[string]map[world.Direction]string{"A": {world.North: "B"}},
+			map[int]string{0: "A", 1: "B"},
+			[]*ScriptedEvent{{Action: CLOSE_ROAD, City: "A", Direction: "north"}},
+			10, ALL_ALIENS_STUCK, 1, map[int]string{0: "A", 1: "B"}, ROAD_CLOSED,
+		},
+		{
+			"open road",
+			nil,
+			map[int]string{0: "A", 1: "B"},
+			[]*ScriptedEvent{{Action: OPEN_ROAD, When: Condition{Round: 2}, City: "A", Direction: "north", To: "B"}},
+			10, NO_ALIENS_LEFT, 3, map[int]string{}, ROAD_OPENED,
+		},
+		{
+			"nuke",
+			map[string]map[world.Direction]string{"A": {world.North: "B"}},
+			map[int]string{0: "A"},
+			[]*ScriptedEvent{{Action: NUKE_CITIES, Cities: []string{"A"}}},
+			10, NO_ALIENS_LEFT, 1, map[int]string{}, CITY_DESTROYED,
+		},
+		{
+			"triggered teleport",
+			nil,
+			map[int]string{0: "A", 1: "B", 2: "C"},
+			[]*ScriptedEvent{
+				{Action: NUKE_CITIES, When: Condition{Round: 1}, Cities: []string{"C"}},
+				{Action: TELEPORT_ALIEN, When: Condition{CityDestroyed: "C"}, City: "B", Aliens: []int{0}},
+			},
+			10, NO_ALIENS_LEFT, 2, map[int]string{}, ALIEN_TELEPORTED,
+		},
+		{
+			"storm",
+			map[string]map[world.Direction]string{"A": {world.North: "B"}, "B": {world.South: "A"}},
+			map[int]string{0: "A", 1: "B"},
+			[]*ScriptedEvent{{Action: STORM, Cities: []string{"A", "B"}, Rounds: 3}},
+			3, MAX_ROUND_REACHED, 3, map[int]string{0: "A", 1: "B"}, STORM_STARTED,
+		},
+	}
+
+	for _, test := range tests {
+		cities := world.CityMap{"A": city.NewCity("A"), "B": city.NewCity("B"), "C": city.NewCity("C")}
+		links := make(world.LinkMap)
+		for from, roads := range test.links {
+			links[from] = make(map[world.Direction]*city.City)
+			for d, to := range roads {
+				links[from][d] = cities[to]
+			}
+		}
+		aliens := make(world.AliensMap)
+		for id, name := range test.aliens {
+			aliens[id] = alien.NewAlien(id, cities[name])
+		}
+
+		kinds := make(map[EventKind]int)
+		e := &Engine{
+			World:   world.NewWorld(cities, links, aliens),
+			MaxRuns: test.maxRuns,
+			Script:  test.script,
+			OnEvent: func(ev Event) { kinds[ev.Kind]++ },
+		}
+
+		report, err := e.Run()
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", test.name, err)
+		}
+		if report.Status != test.wantedStatus || report.Rounds != test.wantedRounds {
+			t.Errorf("%s: expected status %d after %d rounds, got %d after %d", test.name, test.wantedStatus, test.wantedRounds, report.Status, report.Rounds)
+		}
+		if len(report.Survivors) != len(test.wantedCities) {
+			t.Errorf("%s: expected %d survivors, got %+v", test.name, len(test.wantedCities), report.Survivors)
+		}
+		for _, s := range report.Survivors {
+			if s.City != test.wantedCities[s.Id] {
+				t.Errorf("%s: expected alien %d in %s, got %s", test.name, s.Id, test.wantedCities[s.Id], s.City)
+			}
+		}
+		if kinds[test.wantedEvent] == 0 {
+			t.Errorf("%s: expected a %s event, got %v", test.name, EventKindString(test.wantedEvent), kinds)
+		}
+		for _, ev := range test.script {
+			if !ev.fired {
+				t.Errorf("%s: expected the %s event to fire", test.name, ev.Action)
+			}
+		}
+	}
+}
+
+func TestScriptErrors(t *testing.T) {
+	var tests = []*ScriptedEvent{
+		{Action: "flood"},
+		{Action: CLOSE_ROAD, City: "A", Direction: "up"},
+		{Action: CLOSE_ROAD, Direction: "north"},
+		{Action: OPEN_ROAD, City: "A", Direction: "north", To: "Z"},
+		{Action: OPEN_ROAD, City: "A", Direction: "north"},
+		{Action: OPEN_ROAD, City: "A", Direction: "north", To: "B", Road: "w=0"},
+		{Action: NUKE_CITIES, Cities: []string{"Z"}},
+		{Action: NUKE_CITIES},
+		{Action: TELEPORT_ALIEN, City: "B", Aliens: []int{7}},
+		{Action: TELEPORT_ALIEN, City: "B"},
+		{Action: STORM, Cities: []string{"A"}},
+		{Action: STORM, Rounds: 2},
+	}
+
+	for _, ev := range tests {
+		cityA, cityB := city.NewCity("A"), city.NewCity("B")
+		e := &Engine{
+			World:   world.NewWorld(world.CityMap{"A": cityA, "B": cityB}, world.LinkMap{}, world.AliensMap{0: alien.NewAlien(0, cityA)}),
+			MaxRuns: 10,
+			Script:  []*ScriptedEvent{ev},
+		}
+		if _, err := e.Run(); err == nil {
+			t.Errorf("%+v: expected error, got nil", ev)
+		}
+	}
+}
+
+func TestSkippedEvents(t *testing.T) {
+	var tests = []struct {
+		name            string
+		event           *ScriptedEvent
+		wantedTeleports int
+	}{
+		{"dead alien", &ScriptedEvent{Action: TELEPORT_ALIEN, City: "A", Aliens: []int{0}}, 0},
+		{"some dead aliens", &ScriptedEvent{Action: TELEPORT_ALIEN, City: "A", Aliens: []int{0, 2}}, 1},
+		{"destroyed city", &ScriptedEvent{Action: TELEPORT_ALIEN, City: "C", Aliens: []int{2}}, 0},
+		{"existing road", &ScriptedEvent{Action: OPEN_ROAD, City: "A", Direction: "north", To: "B"}, 0},
+		{"missing road", &ScriptedEvent{Action: CLOSE_ROAD, City: "B", Direction: "east"}, 0},
+	}
+
+	for _, test := range tests {
+		// Aliens 0 and 1 destroy each other and C during the first round, while alien 2 is stuck in D
+		var (
+			cityA, cityB, cityC, cityD = city.NewCity("A"), city.NewCity("B"), city.NewCity("C"), city.NewCity("D")
+			links                      = world.LinkMap{"A": {world.North: cityC}, "B": {world.North: cityC}}
+			aliens                     = world.AliensMap{0: alien.NewAlien(0, cityA), 1: alien.NewAlien(1, cityB), 2: alien.NewAlien(2, cityD)}
+		)
+		test.event.When = Condition{Round: 2}
+		kinds := make(map[EventKind]int)
+		e := &Engine{
+			World:   world.NewWorld(world.CityMap{"A": cityA, "B": cityB, "C": cityC, "D": cityD}, links, aliens),
+			MaxRuns: 10,
+			Script:  []*ScriptedEvent{test.event},
+			OnEvent: func(ev Event) { kinds[ev.Kind]++ },
+		}
+
+		if _, err := e.Run(); err != nil {
+			t.Fatalf("%s: expected no error, got %v", test.name, err)
+		}
+		if !test.event.fired || kinds[EVENT_SKIPPED] != 1 || kinds[ALIEN_TELEPORTED] != test.wantedTeleports {
+			t.Errorf("%s: expected the event to be skipped after %d teleports, got %v", test.name, test.wantedTeleports, kinds)
+		}
+	}
+}
+
+func TestNukeReport(t *testing.T) {
+	cityA := city.NewCity("A")
+	e := &Engine{
+		World:   world.NewWorld(world.CityMap{"A": cityA}, world.LinkMap{}, world.AliensMap{0: alien.NewAlien(0, cityA)}),
+		MaxRuns: 10,
+		Script:  []*ScriptedEvent{{Action: NUKE_CITIES, Cities: []string{"A"}}},
+	}
+
+	report, err := e.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.DestroyedCities) != 1 || formatDestroyers(report.DestroyedCities[0], " and ") != "a scripted event" {
+		t.Errorf("Unexpected destroyed cities %+v", report.DestroyedCities)
+	}
+}
@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Group of aliens arriving together
+type Wave struct {
+	Round  int      // Round the wave arrives at the beginning of
+	Count  int      // Number of aliens of the wave
+	Cities []string // Cities the aliens may spawn in (any surviving city if empty)
+}
+
+// Schedule of the aliens arriving during the execution, besides the ones deployed at the beginning
+type SpawnSchedule struct {
+	Waves []Wave // Waves of aliens, in any order
+
+	Rate   float64  // Number of aliens spawning each round (fractions accumulate over the rounds)
+	Cities []string // Cities the continuous spawn may happen in (any surviving city if empty)
+	Until  int      // Last round of the continuous spawn (no limit if zero)
+
+	accumulated float64 // Fraction of alien accumulated by the continuous spawn
+}
+
+// Method that checks whether more aliens will arrive from the given round on
+func (s *SpawnSchedule) Pending(round int) bool {
+	if s == nil {
+		return false
+	}
+	if s.Rate > 0 && (s.Until == 0 || round <= s.Until) {
+		return true
+	}
+	for _, w := range s.Waves {
+		if w.Round >= round {
+			return true
+		}
+	}
+	return false
+}
+
+// Method that checks that the cities the aliens may spawn in exist in the world, so that misspelled ones are not
+// silently skipped
+func (s *SpawnSchedule) Validate(w *world.World) error {
+	if s == nil {
+		return nil
+	}
+
+	cities := append([]string{}, s.Cities...)
+	for _, wave := range s.Waves {
+		cities = append(cities, wave.Cities...)
+	}
+	for _, name := range cities {
+		if _, exists := w.Cities[name]; !exists {
+			return fmt.Errorf("invalid spawn city: %w", &world.CityNotFoundError{Name: name})
+		}
+	}
+	return nil
+}
+
+// Method that spawns the aliens arriving at the beginning of the current round
+func (e *Engine) spawn() error {
+	s := e.Spawn
+	if s == nil {
+		return nil
+	}
+
+	for _, w := range s.Waves {
+		if w.Round == e.Runs {
+			if err := e.spawnAliens(w.Count, w.Cities); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.Rate > 0 && (s.Until == 0 || e.Runs <= s.Until) {
+		s.accumulated += s.Rate
+		count := int(s.accumulated)
+		s.accumulated -= float64(count)
+		return e.spawnAliens(count, s.Cities)
+	}
+	return nil
+}
+
+// Method that spawns a number of aliens in random surviving cities among the given ones (any of them if empty)
+func (e *Engine) spawnAliens(count int, cities []string) error {
+	if count == 0 {
+		return nil
+	}
+	if len(cities) == 0 {
+		cities = e.World.SortedCityNames()
+	}
+
+	available := make([]string, 0, len(cities))
+	for _, name := range cities {
+		if c, exists := e.World.Cities[name]; exists && !c.Destroyed {
+			available = append(available, name)
+		}
+	}
+	if len(available) == 0 {
+		log.Printf("No surviving cities to spawn %d aliens in", count)
+		return nil
+	}
+
+	for i := 0; i < count; i++ {
+		al, err := e.World.SpawnAlien(available[utils.RandomInt(len(available))])
+		if err != nil {
+			return err
+		}
+		log.Printf("%s spawned in city %s", al, al.City.Name)
+		e.emit(Event{Kind: ALIEN_SPAWNED, Aliens: []int{al.Id}, City: al.City.Name})
+	}
+	return nil
+}
+
+// Function that parses a comma separated list of waves, in the format round:count[@city/city]
+// (e.g. "3:5@Foo/Bar,10:8")
+func ParseWaves(s string) ([]Wave, error) {
+	waves := make([]Wave, 0)
+	if strings.TrimSpace(s) == "" {
+		return waves, nil
+	}
+
+	for _, item := range strings.Split(s, ",") {
+		spec, cities := strings.TrimSpace(item), ""
+		if sep := strings.Index(spec, "@"); sep != -1 {
+			spec, cities = spec[:sep], spec[sep+1:]
+		}
+
+		parts := strings.Split(spec, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid wave: %s", item)
+		}
+		round, err := strconv.Atoi(parts[0])
+		if err != nil || round < 0 {
+			return nil, fmt.Errorf("invalid wave round: %s", parts[0])
+		}
+		count, err := strconv.Atoi(parts[1])
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid wave size: %s", parts[1])
+		}
+
+		w := Wave{Round: round, Count: count}
+		if cities != "" {
+			w.Cities = strings.Split(cities, "/")
+		}
+		waves = append(waves, w)
+	}
+	return waves, nil
+}
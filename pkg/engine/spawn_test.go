@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+func TestParseWaves(t *testing.T) {
+	var tests = []struct {
+		input       string
+		expectError bool
+		wanted      int
+	}{
+		{"", false, 0},
+		{"3:5@Foo/Bar,10:8", false, 2},
+		{"3", true, 0},
+		{"-1:5", true, 0},
+		{"3:0", true, 0},
+		{"a:5", true, 0},
+	}
+
+	for _, test := range tests {
+		waves, err := ParseWaves(test.input)
+		if (err != nil) != test.expectError {
+			t.Errorf("%s: expected error %t, got %v", test.input, test.expectError, err)
+			continue
+		}
+		if len(waves) != test.wanted {
+			t.Errorf("%s: expected %d waves, got %d", test.input, test.wanted, len(waves))
+		}
+	}
+
+	waves, _ := ParseWaves("3:5@Foo/Bar")
+	if w := waves[0]; w.Round != 3 || w.Count != 5 || len(w.Cities) != 2 || w.Cities[1] != "Bar" {
+		t.Errorf("Unexpected wave %+v", w)
+	}
+}
+
+func TestSpawnWaves(t *testing.T) {
+	var (
+		cityA = city.NewCity("A")
+		cityB = city.NewCity("B")
+	)
+
+	spawned := make([]Event, 0)
+	e := &Engine{
+		World:   world.NewWorld(world.CityMap{"A": cityA, "B": cityB}, world.LinkMap{}, world.AliensMap{}),
+		MaxRuns: 10,
+		Spawn:   &SpawnSchedule{Waves: []Wave{{Round: 2, Count: 2, Cities: []string{"A"}}}},
+		OnEvent: func(ev Event) {
+			if ev.Kind == ALIEN_SPAWNED {
+				spawned = append(spawned, ev)
+			}
+		},
+	}
+
+	report, err := e.Run()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The run waits for the wave, whose aliens land in A and destroy it
+	if report.Status != NO_ALIENS_LEFT || report.Rounds != 3 {
+		t.Errorf("Expected no aliens left after 3 rounds, got status %d after %d rounds", report.Status, report.Rounds)
+	}
+	if len(spawned) != 2 || spawned[0].City != "A" || spawned[0].Round != 2 || spawned[1].Aliens[0] != 1 {
+		t.Errorf("Unexpected spawn events %+v", spawned)
+	}
+	if len(report.DestroyedCities) != 1 || report.DestroyedCities[0].City != "A" {
+		t.Errorf("Expected A to be destroyed, got %+v", report.DestroyedCities)
+	}
+}
+
+func TestSpawnRate(t *testing.T) {
+	var (
+		cityA = city.NewCity("A")
+		cityB = city.NewCity("B")
+	)
+
+	spawned := make([]int, 0)
+	e := &Engine{
+		World:   world.NewWorld(world.CityMap{"A": cityA, "B": cityB}, world.LinkMap{}, world.AliensMap{}),
+		MaxRuns: 10,
+		Spawn:   &SpawnSchedule{Rate: 0.5, Cities: []string{"B"}, Until: 3},
+		OnEvent: func(ev Event) {
+			if ev.Kind == ALIEN_SPAWNED {
+				spawned = append(spawned, ev.Round)
+			}
+		},
+	}
+
+	if _, err := e.Run(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Half an alien per round, from round 0 to round 3
+	if len(spawned) != 2 || spawned[0] != 1 || spawned[1] != 3 {
+		t.Errorf("Expected aliens to spawn at rounds 1 and 3, got %v", spawned)
+	}
+	if cityA.Destroyed || !cityB.Destroyed {
+		t.Errorf("Expected aliens to spawn in B only")
+	}
+}
+
+func TestSpawnCities(t *testing.T) {
+	w := world.NewWorld(world.CityMap{"A": city.NewCity("A")}, world.LinkMap{}, world.AliensMap{})
+
+	var tests = []struct {
+		name     string
+		schedule *SpawnSchedule
+		valid    bool
+	}{
+		{"none", nil, true},
+		{"any city", &SpawnSchedule{Rate: 1}, true},
+		{"known cities", &SpawnSchedule{Rate: 1, Cities: []string{"A"}, Waves: []Wave{{Round: 1, Count: 1, Cities: []string{"A"}}}}, true},
+		{"misspelled spawn point", &SpawnSchedule{Rate: 1, Cities: []string{"a"}}, false},
+		{"misspelled wave city", &SpawnSchedule{Waves: []Wave{{Round: 1, Count: 1, Cities: []string{"A", "B"}}}}, false},
+	}
+
+	for _, test := range tests {
+		err := test.schedule.Validate(w)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.name, test.valid, err)
+		}
+		if err != nil && !errors.As(err, new(*world.CityNotFoundError)) {
+			t.Errorf("%s: expected a CityNotFoundError, got %v", test.name, err)
+		}
+	}
+
+	// Invalid schedules are rejected before the first round
+	e := &Engine{World: w, MaxRuns: 10, Spawn: tests[3].schedule}
+	if _, err := e.Run(); err == nil || e.Runs != 0 {
+		t.Errorf("Expected error before running, got %v after %d rounds", err, e.Runs)
+	}
+}
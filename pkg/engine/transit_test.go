@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+func TestHeadOnCollisions(t *testing.T) {
+	var tests = []struct {
+		headOn       bool
+		wantedStatus ExecutionStatus
+		wantedCities []string // Final city of each alien, if it survived
+	}{
+		// The aliens cross each other without noticing and arrive at the third round
+		{false, MAX_ROUND_REACHED, []string{"B", "A"}},
+		{true, NO_ALIENS_LEFT, nil},
+	}
+
+	for _, test := range tests {
+		var (
+			cityA = city.NewCity("A")
+			cityB = city.NewCity("B")
+		)
+		w := world.NewWorld(
+			world.CityMap{"A": cityA, "B": cityB},
+			world.LinkMap{
+				"A": map[world.Direction]*city.City{world.North: cityB},
+				"B": map[world.Direction]*city.City{world.South: cityA},
+			},
+			world.AliensMap{0: alien.NewAlien(0, cityA), 1: alien.NewAlien(1, cityB)},
+		)
+		w.Roads = world.RoadMap{
+			"A": map[world.Direction]world.Road{world.North: {Weight: 1, Time: 3}},
+			"B": map[world.Direction]world.Road{world.South: {Weight: 1, Time: 3}},
+		}
+
+		collisions := 0
+		e := &Engine{
+			World:            w,
+			MaxRuns:          3,
+			HeadOnCollisions: test.headOn,
+			OnEvent: func(ev Event) {
+				if ev.Kind == HEAD_ON_COLLISION {
+					collisions++
+				}
+			},
+		}
+
+		report, err := e.Run()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if report.Status != test.wantedStatus {
+			t.Errorf("Expected status %d, got %d", test.wantedStatus, report.Status)
+		}
+		if len(report.Survivors) != len(test.wantedCities) {
+			t.Fatalf("Expected %d survivors, got %+v", len(test.wantedCities), report.Survivors)
+		}
+		for i, name := range test.wantedCities {
+			if report.Survivors[i].City != name || report.Survivors[i].TravelTo != "" {
+				t.Errorf("Expected alien %d in %s, got %+v", i, name, report.Survivors[i])
+			}
+		}
+		// No city is damaged by aliens fighting on the roads
+		if cityA.Destroyed || cityB.Destroyed {
+			t.Errorf("Expected the cities to survive")
+		}
+		if test.headOn != (collisions == 1) {
+			t.Errorf("Expected head-on collisions %t, got %d collisions", test.headOn, collisions)
+		}
+	}
+}
@@ -4,10 +4,11 @@ import "github.com/AzraelSec/mad-aliens/pkg/world"
 
 // Constants that define the engine execution status
 const (
-	RUNNING           = iota + 1 // Still running
-	MAX_ROUND_REACHED            // Completed because of max round reached
-	ALL_ALIENS_STUCK             // All the aliens are stuck and next executions would not change
-	NO_ALIENS_LEFT               // All the aliens are dead fighting
+	RUNNING            = iota + 1 // Still running
+	MAX_ROUND_REACHED             // Completed because of max round reached
+	ALL_ALIENS_STUCK              // All the aliens are stuck and next executions would not change
+	NO_ALIENS_LEFT                // All the aliens are dead fighting
+	NO_POSSIBLE_FIGHTS            // No two alive aliens can ever meet again
 )
 
 type ExecutionStatus int
@@ -17,4 +18,22 @@ type Engine struct {
 	MaxRuns int          // Max number of execution rounds
 	Runs    int          // Number of execution rounds already performed
 	World   *world.World // Pointer to the world the engine should manage
+
+	Diplomacy *Diplomacy     // Relationships between factions (default rules if nil)
+	Combat    CombatResolver // Resolver of the fights (mutual destruction if nil)
+
+	WaitProbability  float64                  // Probability of each alien to stay in its city for a round, instead of moving
+	HeadOnCollisions bool                     // A boolean indicating if aliens traveling the same road in opposite directions fight
+	RebuildAfter     int                      // Number of rounds after which an empty destroyed city gets rebuilt (never if zero)
+	Spawn            *SpawnSchedule           // Schedule of the aliens arriving during the execution (none if nil)
+	OnEvent          func(Event)              // Handler notified of every event happening during the execution (optional)
+	Script           []*ScriptedEvent         // Events of the world scripted in advance (none if empty)
+	Ignore           map[ExecutionStatus]bool // Ending conditions that do not stop the execution, besides the max round (none if nil)
+	Workers          int                      // Number of goroutines choosing the aliens moves in parallel (sequential if zero)
+	Trajectories     bool                     // A boolean indicating if the run report includes the aliens paths
+
+	destructions []CityDestruction // Cities destroyed so far, in the order they were destroyed
+	rebuilds     []CityRebuild     // Cities rebuilt so far, in the order they were rebuilt
+	frozen       map[string]int    // Last round each city hit by a storm is frozen during
+	fights       *fightsCheck      // Outcome of the last check of the possible fights
 }
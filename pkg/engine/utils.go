@@ -1,5 +1,10 @@
 package engine
 
+import (
+	"fmt"
+	"strings"
+)
+
 func ExecStatusString(s ExecutionStatus) string {
 	switch s {
 	case MAX_ROUND_REACHED:
@@ -8,7 +13,62 @@ func ExecStatusString(s ExecutionStatus) string {
 		return "All alive aliens are stuck"
 	case NO_ALIENS_LEFT:
 		return "No alive aliens left"
+	case NO_POSSIBLE_FIGHTS:
+		return "No alive aliens can meet anymore"
 	default:
 		return "Unhandled exit status"
 	}
 }
+
+func EventKindString(k EventKind) string {
+	switch k {
+	case ALIEN_MOVED:
+		return "AlienMoved"
+	case ALIEN_WAITED:
+		return "AlienWaited"
+	case ALIEN_STUCK:
+		return "AlienStuck"
+	case ALIEN_KILLED:
+		return "AlienKilled"
+	case CITY_DESTROYED:
+		return "CityDestroyed"
+	case ALIEN_DEPARTED:
+		return "AlienDeparted"
+	case HEAD_ON_COLLISION:
+		return "HeadOnCollision"
+	case CITY_DAMAGED:
+		return "CityDamaged"
+	case CITY_REBUILT:
+		return "CityRebuilt"
+	case ALIEN_SPAWNED:
+		return "AlienSpawned"
+	case DEFENDER_KILLED:
+		return "DefenderKilled"
+	case ROAD_CLOSED:
+		return "RoadClosed"
+	case ROAD_OPENED:
+		return "RoadOpened"
+	case ALIEN_TELEPORTED:
+		return "AlienTeleported"
+	case STORM_STARTED:
+		return "StormStarted"
+	case EVENT_SKIPPED:
+		return "EventSkipped"
+	default:
+		return "UnknownEvent"
+	}
+}
+
+// Function that parses the name of an ending condition that can be ignored (e.g. "all_aliens_stuck")
+func ParseEndingCondition(s string) (ExecutionStatus, error) {
+	switch strings.TrimSpace(s) {
+	case "no_aliens_left":
+		return NO_ALIENS_LEFT, nil
+	case "all_aliens_stuck":
+		return ALL_ALIENS_STUCK, nil
+	case "no_possible_fights":
+		return NO_POSSIBLE_FIGHTS, nil
+	default:
+		return 0, fmt.Errorf("unknown ending condition: %s", s)
+	}
+}
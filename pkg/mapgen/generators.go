@@ -0,0 +1,343 @@
+package mapgen
+
+import (
+	"fmt"
+
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Constants that identify the available map topologies
+const (
+	GRID        = "grid"
+	PLANAR      = "planar"
+	SMALL_WORLD = "smallworld"
+	SCALE_FREE  = "scalefree"
+	MAZE        = "maze"
+	ISLANDS     = "islands"
+)
+
+// Available map topologies, in the order they are documented
+var Topologies = []string{GRID, PLANAR, SMALL_WORLD, SCALE_FREE, MAZE, ISLANDS}
+
+// Options that control the map generation.
+// Since each city can have a single road per direction, no city can have more than 4 neighbors:
+// the non-geometric topologies (small-world and scale-free) are capped accordingly.
+type Options struct {
+	Width    int     // Number of columns of the grid-based topologies (grid, planar, maze, islands)
+	Height   int     // Number of rows of the grid-based topologies (grid, planar, maze, islands)
+	Cities   int     // Number of cities of the graph-based topologies (small-world, scale-free)
+	Symmetry float64 // Probability that a road also has its way back (1 means every road is two-way)
+	Density  float64 // Probability of keeping each optional road (planar, islands)
+	Rewiring float64 // Probability of rewiring each ring road into a random shortcut (small-world)
+	Attach   int     // Number of roads each new city attaches with (scale-free)
+	Islands  int     // Number of islands (islands)
+	Seed     int64   // Seed of the random generator: the same options always generate the same map
+}
+
+// Function that generates a map of the given topology.
+// Like each generator, it returns an error if the size the topology requires (width and height, or number of cities) is not positive.
+func Generate(topology string, opts Options) (*Map, error) {
+	switch topology {
+	case GRID:
+		return Grid(opts)
+	case PLANAR:
+		return Planar(opts)
+	case SMALL_WORLD:
+		return SmallWorld(opts)
+	case SCALE_FREE:
+		return ScaleFree(opts)
+	case MAZE:
+		return Maze(opts)
+	case ISLANDS:
+		return Islands(opts)
+	default:
+		return nil, fmt.Errorf("unknown topology: %s", topology)
+	}
+}
+
+// Function that checks the size of the grid-based topologies
+func validateGrid(opts Options) error {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return fmt.Errorf("invalid map size: %dx%d", opts.Width, opts.Height)
+	}
+	return nil
+}
+
+// Function that checks the size of the graph-based topologies
+func validateCities(opts Options) error {
+	if opts.Cities <= 0 {
+		return fmt.Errorf("invalid number of cities: %d", opts.Cities)
+	}
+	return nil
+}
+
+// Method that adds Width x Height cities, returning them indexed by row and column
+func (m *Map) addGrid(width, height int) [][]string {
+	cells := make([][]string, height)
+	for y := range cells {
+		cells[y] = make([]string, width)
+		for x := range cells[y] {
+			cells[y][x] = m.addCity()
+		}
+	}
+	return cells
+}
+
+// Method that adds a road between two cities whose relative position is given by the direction.
+// The road orientation is picked randomly, so that one-way roads do not all point the same way.
+func (m *Map) road(a, b string, d world.Direction) bool {
+	if !m.free(a, d) || !m.free(b, world.Opposite(d)) {
+		return false
+	}
+
+	if m.rng.Float64() < 0.5 {
+		m.connect(a, b, d)
+	} else {
+		m.connect(b, a, world.Opposite(d))
+	}
+	return true
+}
+
+// Grid edge between two adjacent cells, where b lies in direction d from a
+type gridEdge struct {
+	a, b string
+	d    world.Direction
+}
+
+// Function that lists the edges between the adjacent cells of a grid that satisfy the filter
+func gridEdges(cells [][]string, keep func(a, b string) bool) []gridEdge {
+	edges := make([]gridEdge, 0)
+	for y, row := range cells {
+		for x, name := range row {
+			if x+1 < len(row) && keep(name, row[x+1]) {
+				edges = append(edges, gridEdge{name, row[x+1], world.East})
+			}
+			if y+1 < len(cells) && keep(name, cells[y+1][x]) {
+				edges = append(edges, gridEdge{name, cells[y+1][x], world.South})
+			}
+		}
+	}
+	return edges
+}
+
+// Method that splits the edges into a random spanning forest and the remaining ones.
+// The forest is built with a randomized Kruskal algorithm, so that it is a uniformly shuffled maze.
+func (m *Map) spanningForest(edges []gridEdge) (tree []gridEdge, rest []gridEdge) {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(n string) string {
+		if p, exists := parent[n]; exists && p != n {
+			parent[n] = find(p)
+			return parent[n]
+		}
+		return n
+	}
+
+	m.rng.Shuffle(len(edges), func(i, j int) { edges[i], edges[j] = edges[j], edges[i] })
+	for _, e := range edges {
+		if ra, rb := find(e.a), find(e.b); ra != rb {
+			parent[ra] = rb
+			tree = append(tree, e)
+		} else {
+			rest = append(rest, e)
+		}
+	}
+	return tree, rest
+}
+
+// Function that generates a rectangular grid where each city is linked to its adjacent ones
+func Grid(opts Options) (*Map, error) {
+	if err := validateGrid(opts); err != nil {
+		return nil, err
+	}
+	m := newMap(opts)
+	cells := m.addGrid(opts.Width, opts.Height)
+	for _, e := range gridEdges(cells, func(_, _ string) bool { return true }) {
+		m.road(e.a, e.b, e.d)
+	}
+	return m, nil
+}
+
+// Function that generates a perfect maze: a grid where exactly one path connects any two cities
+// (if roads are two-way)
+func Maze(opts Options) (*Map, error) {
+	if err := validateGrid(opts); err != nil {
+		return nil, err
+	}
+	m := newMap(opts)
+	cells := m.addGrid(opts.Width, opts.Height)
+	tree, _ := m.spanningForest(gridEdges(cells, func(_, _ string) bool { return true }))
+	for _, e := range tree {
+		m.road(e.a, e.b, e.d)
+	}
+	return m, nil
+}
+
+// Function that generates a random connected planar graph.
+// It is a maze whose remaining grid edges are added with the Density probability:
+// since only adjacent cells are linked, no two roads ever cross.
+func Planar(opts Options) (*Map, error) {
+	if err := validateGrid(opts); err != nil {
+		return nil, err
+	}
+	m := newMap(opts)
+	cells := m.addGrid(opts.Width, opts.Height)
+	tree, rest := m.spanningForest(gridEdges(cells, func(_, _ string) bool { return true }))
+	for _, e := range tree {
+		m.road(e.a, e.b, e.d)
+	}
+	for _, e := range rest {
+		if m.rng.Float64() < opts.Density {
+			m.road(e.a, e.b, e.d)
+		}
+	}
+	return m, nil
+}
+
+// Function that generates a grid split into disconnected islands.
+// Islands grow at the same time from random cells until the grid is covered,
+// then each island is connected internally like a planar map.
+func Islands(opts Options) (*Map, error) {
+	if err := validateGrid(opts); err != nil {
+		return nil, err
+	}
+	m := newMap(opts)
+	cells := m.addGrid(opts.Width, opts.Height)
+
+	type cell struct{ x, y int }
+	var (
+		island   = make(map[string]int, len(m.Cities))
+		frontier = make([]cell, 0)
+		nIslands = maxInt(1, minInt(opts.Islands, len(m.Cities)))
+	)
+
+	for _, idx := range m.rng.Perm(len(m.Cities))[:nIslands] {
+		c := cell{idx % opts.Width, idx / opts.Width}
+		island[cells[c.y][c.x]] = len(frontier)
+		frontier = append(frontier, c)
+	}
+
+	// Randomized multi-source flood fill: each island is a connected region of the grid
+	for len(frontier) > 0 {
+		i := m.rng.Intn(len(frontier))
+		c := frontier[i]
+		frontier[i] = frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+
+		for _, d := range world.Directions {
+			nx, ny := c.x, c.y
+			switch d {
+			case world.North:
+				ny--
+			case world.East:
+				nx++
+			case world.South:
+				ny++
+			case world.West:
+				nx--
+			}
+			if nx < 0 || ny < 0 || nx >= opts.Width || ny >= opts.Height {
+				continue
+			}
+			if _, assigned := island[cells[ny][nx]]; !assigned {
+				island[cells[ny][nx]] = island[cells[c.y][c.x]]
+				frontier = append(frontier, cell{nx, ny})
+			}
+		}
+	}
+
+	tree, rest := m.spanningForest(gridEdges(cells, func(a, b string) bool { return island[a] == island[b] }))
+	for _, e := range tree {
+		m.road(e.a, e.b, e.d)
+	}
+	for _, e := range rest {
+		if m.rng.Float64() < opts.Density {
+			m.road(e.a, e.b, e.d)
+		}
+	}
+	return m, nil
+}
+
+// Function that generates a small-world network (Watts-Strogatz model).
+// Cities are placed on a ring and linked to their first and second successors,
+// then each road is replaced by a shortcut to a random city with the Rewiring probability.
+func SmallWorld(opts Options) (*Map, error) {
+	if err := validateCities(opts); err != nil {
+		return nil, err
+	}
+	m := newMap(opts)
+	for i := 0; i < opts.Cities; i++ {
+		m.addCity()
+	}
+
+	n := len(m.Cities)
+	for i, name := range m.Cities {
+		for _, ring := range []struct {
+			offset int
+			d      world.Direction
+		}{{1, world.East}, {2, world.North}} {
+			offset, d := ring.offset, ring.d
+			if n <= offset {
+				continue
+			}
+
+			if m.rng.Float64() < opts.Rewiring {
+				if m.connectAny(name, m.Cities[m.rng.Intn(n)]) {
+					continue
+				}
+			}
+
+			if next := m.Cities[(i+offset)%n]; !m.road(name, next, d) {
+				m.connectAny(name, next)
+			}
+		}
+	}
+	return m, nil
+}
+
+// Function that generates a scale-free network (Barabasi-Albert model).
+// Each new city attaches to existing cities with a probability proportional to their degree;
+// cities whose directions are all taken are not eligible anymore.
+func ScaleFree(opts Options) (*Map, error) {
+	if err := validateCities(opts); err != nil {
+		return nil, err
+	}
+	m := newMap(opts)
+	attach := maxInt(1, minInt(opts.Attach, len(world.Directions)))
+
+	// Each city appears in ends once per road it is involved in, plus once when it is created,
+	// so that picking a random item follows the preferential attachment rule
+	ends := make([]string, 0)
+	for i := 0; i < opts.Cities; i++ {
+		name := m.addCity()
+		for linked := 0; linked < attach && linked < i; {
+			attempts := 0
+			for ; attempts < 16; attempts++ {
+				if target := ends[m.rng.Intn(len(ends))]; !m.linked(name, target) && m.connectAny(name, target) {
+					ends = append(ends, name, target)
+					break
+				}
+			}
+			if attempts == 16 {
+				break
+			}
+			linked++
+		}
+		ends = append(ends, name)
+	}
+	return m, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
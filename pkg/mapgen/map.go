@@ -0,0 +1,138 @@
+package mapgen
+
+import (
+	"io"
+	"math/rand"
+	"strings"
+	"unicode"
+
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Generated world map, kept in a format-agnostic way until it gets serialized
+type Map struct {
+	Cities []string                              // City names, in the order they are written out
+	Roads  map[string]map[world.Direction]string // A map that associates each city name to its outgoing roads
+
+	rng      *rand.Rand
+	symmetry float64
+}
+
+// Function to instanciate a new empty Map
+func newMap(opts Options) *Map {
+	return &Map{
+		Cities:   make([]string, 0),
+		Roads:    make(map[string]map[world.Direction]string),
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		symmetry: opts.Symmetry,
+	}
+}
+
+// Method that adds a new city to the map and returns its name
+func (m *Map) addCity() string {
+	name := cityName(len(m.Cities))
+	m.Cities = append(m.Cities, name)
+	return name
+}
+
+// Method that checks whether a city has no road in the given direction yet
+func (m *Map) free(name string, d world.Direction) bool {
+	_, taken := m.Roads[name][d]
+	return !taken
+}
+
+// Method that adds a one-way road, overriding any other road in the same direction
+func (m *Map) setRoad(from, to string, d world.Direction) {
+	if m.Roads[from] == nil {
+		m.Roads[from] = make(map[world.Direction]string)
+	}
+	m.Roads[from][d] = to
+}
+
+// Method that connects two cities with a road in the given direction.
+// Depending on the symmetry option, the way back is added as well (if the opposite direction is free).
+func (m *Map) connect(from, to string, d world.Direction) {
+	m.setRoad(from, to, d)
+	if back := world.Opposite(d); m.free(to, back) && m.rng.Float64() < m.symmetry {
+		m.setRoad(to, from, back)
+	}
+}
+
+// Method that connects two cities using any direction which is free on both ends.
+// It is used by the topologies that have no geometric meaning, and fails when no such direction exists.
+func (m *Map) connectAny(from, to string) bool {
+	candidates := make([]world.Direction, 0, len(world.Directions))
+	for _, d := range world.Directions {
+		if m.free(from, d) && m.free(to, world.Opposite(d)) {
+			candidates = append(candidates, d)
+		}
+	}
+	if from == to || len(candidates) == 0 {
+		return false
+	}
+
+	m.connect(from, to, candidates[m.rng.Intn(len(candidates))])
+	return true
+}
+
+// Method that checks whether two cities are already linked by a road, in any orientation
+func (m *Map) linked(a, b string) bool {
+	for _, target := range m.Roads[a] {
+		if target == b {
+			return true
+		}
+	}
+	for _, target := range m.Roads[b] {
+		if target == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Method that serializes the map with the same format used to define a world.
+// Cities without outgoing roads are written on their own, so that they are not lost.
+func (m *Map) String() string {
+	var b strings.Builder
+	for _, name := range m.Cities {
+		b.WriteString(name)
+		for _, d := range world.Directions {
+			if target, exists := m.Roads[name][d]; exists {
+				directionStr, _ := world.DirectionString(d)
+				b.WriteString(" " + directionStr + "=" + target)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Method that writes the serialized map to a writer
+func (m *Map) WriteTo(out io.Writer) (int64, error) {
+	n, err := io.WriteString(out, m.String())
+	return int64(n), err
+}
+
+// Method that parses the generated map into a world, deploying the given number of aliens
+func (m *Map) World(nAliens int) (*world.World, error) {
+	return world.Parse(strings.NewReader(m.String()), nAliens)
+}
+
+var (
+	consonants = []rune("bcdfghjklmnprstvz")
+	vowels     = []rune("aeiou")
+)
+
+// Function that deterministically builds a unique, pronounceable city name from an index.
+// Names are made of consonant-vowel syllables and never contain numeric characters,
+// in accordance with the world definition assumptions.
+func cityName(idx int) string {
+	syllables := len(consonants) * len(vowels)
+	name := make([]rune, 0, 8)
+	for n := idx + 1; n > 0; n = (n - 1) / syllables {
+		s := (n - 1) % syllables
+		name = append(name, consonants[s/len(vowels)], vowels[s%len(vowels)])
+	}
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
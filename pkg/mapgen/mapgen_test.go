@@ -0,0 +1,191 @@
+package mapgen
+
+import (
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Function that counts the groups of cities connected by roads, regardless of their orientation
+func countComponents(m *Map) int {
+	adjacent := make(map[string][]string)
+	for from, roads := range m.Roads {
+		for _, to := range roads {
+			adjacent[from] = append(adjacent[from], to)
+			adjacent[to] = append(adjacent[to], from)
+		}
+	}
+
+	visited, components := make(map[string]bool), 0
+	for _, root := range m.Cities {
+		if visited[root] {
+			continue
+		}
+		components++
+		visited[root] = true
+		for queue := []string{root}; len(queue) > 0; queue = queue[1:] {
+			for _, next := range adjacent[queue[0]] {
+				if !visited[next] {
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	return components
+}
+
+// Function that counts the roads of a map
+func countRoads(m *Map) int {
+	n := 0
+	for _, roads := range m.Roads {
+		n += len(roads)
+	}
+	return n
+}
+
+func TestGenerate(t *testing.T) {
+	var tests = []struct {
+		topology         string
+		opts             Options
+		wantedCities     int
+		wantedRoads      int // -1 if not deterministic
+		wantedComponents int // -1 if not deterministic
+	}{
+		// 3x2 grid: 7 two-way roads
+		{GRID, Options{Width: 3, Height: 2, Symmetry: 1}, 6, 14, 1},
+		// One-way grid
+		{GRID, Options{Width: 3, Height: 2, Symmetry: 0}, 6, 7, 1},
+		// Perfect maze: a spanning tree of the grid
+		{MAZE, Options{Width: 5, Height: 4, Symmetry: 1}, 20, 38, 1},
+		// Planar map without optional roads is a maze
+		{PLANAR, Options{Width: 5, Height: 4, Symmetry: 1, Density: 0}, 20, 38, 1},
+		// Planar map with every optional road is a grid
+		{PLANAR, Options{Width: 5, Height: 4, Symmetry: 1, Density: 1}, 20, 62, 1},
+		// Islands are disconnected
+		{ISLANDS, Options{Width: 6, Height: 6, Symmetry: 1, Islands: 3}, 36, -1, 3},
+		// Ring lattice without rewiring
+		{SMALL_WORLD, Options{Cities: 10, Symmetry: 1}, 10, 40, 1},
+		// Small-world network
+		{SMALL_WORLD, Options{Cities: 50, Symmetry: 0.5, Rewiring: 0.3}, 50, -1, -1},
+		// Scale-free tree
+		{SCALE_FREE, Options{Cities: 30, Symmetry: 1, Attach: 1}, 30, 58, 1},
+	}
+
+	for _, test := range tests {
+		for seed := int64(0); seed < 10; seed++ {
+			test.opts.Seed = seed
+			m, err := Generate(test.topology, test.opts)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(m.Cities) != test.wantedCities {
+				t.Errorf("%s: expected %d cities, got %d", test.topology, test.wantedCities, len(m.Cities))
+			}
+
+			if n := countRoads(m); test.wantedRoads != -1 && n != test.wantedRoads {
+				t.Errorf("%s: expected %d roads, got %d", test.topology, test.wantedRoads, n)
+			}
+
+			if n := countComponents(m); test.wantedComponents != -1 && n != test.wantedComponents {
+				t.Errorf("%s: expected %d components, got %d", test.topology, test.wantedComponents, n)
+			}
+
+			// Generated maps are always valid world definitions
+			w, err := m.World(2)
+			if err != nil {
+				t.Fatalf("%s: expected a valid map, got %v", test.topology, err)
+			}
+			if len(w.Cities) != test.wantedCities {
+				t.Errorf("%s: expected %d parsed cities, got %d", test.topology, test.wantedCities, len(w.Cities))
+			}
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	var tests = []struct {
+		topology string
+		opts     Options
+	}{
+		{GRID, Options{Width: -3, Height: 2}},
+		{PLANAR, Options{Width: 3, Height: -1}},
+		{MAZE, Options{Width: 0, Height: 4}},
+		{ISLANDS, Options{Islands: 2}},
+		{SMALL_WORLD, Options{Cities: 0}},
+		{SCALE_FREE, Options{Cities: -5, Attach: 1}},
+		{"torus", Options{Width: 3, Height: 3}},
+	}
+
+	for _, test := range tests {
+		if _, err := Generate(test.topology, test.opts); err == nil {
+			t.Errorf("%s %+v: expected error, got nil", test.topology, test.opts)
+		}
+	}
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	opts := Options{Width: -2, Height: -2, Cities: -2, Attach: 1, Islands: 1}
+	for name, generate := range map[string]func(Options) (*Map, error){
+		GRID: Grid, PLANAR: Planar, MAZE: Maze, ISLANDS: Islands, SMALL_WORLD: SmallWorld, SCALE_FREE: ScaleFree,
+	} {
+		if m, err := generate(opts); err == nil || m != nil {
+			t.Errorf("%s: expected error, got %v", name, m)
+		}
+	}
+}
+
+func TestGenerateDeterminism(t *testing.T) {
+	for _, topology := range Topologies {
+		opts := Options{Width: 6, Height: 5, Cities: 30, Symmetry: 0.5, Density: 0.5, Rewiring: 0.2, Attach: 2, Islands: 2, Seed: 42}
+
+		a, _ := Generate(topology, opts)
+		b, _ := Generate(topology, opts)
+		if a.String() != b.String() {
+			t.Errorf("%s: expected the same map for the same seed", topology)
+		}
+	}
+
+	if _, err := Generate("unknown", Options{}); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestGridGeometry(t *testing.T) {
+	m, err := Grid(Options{Width: 4, Height: 4, Symmetry: 1, Seed: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	w, err := m.World(0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Grid roads always follow their direction, so no contradiction is found
+	layout := w.Layout()
+	if len(layout.Contradictions) != 0 || layout.HasOverlaps() {
+		t.Errorf("Expected a consistent layout, got %v", layout.Contradictions)
+	}
+
+	if width, height := layout.Size(); width != 4 || height != 4 {
+		t.Errorf("Expected 4x4 layout, got %dx%d", width, height)
+	}
+
+	for _, roads := range m.Roads {
+		if len(roads) > len(world.Directions) {
+			t.Errorf("Expected at most %d roads per city, got %d", len(world.Directions), len(roads))
+		}
+	}
+}
+
+func TestCityName(t *testing.T) {
+	names := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		name := cityName(i)
+		if names[name] {
+			t.Fatalf("Expected unique names, got %s twice", name)
+		}
+		names[name] = true
+	}
+}
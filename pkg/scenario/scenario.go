@@ -0,0 +1,284 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/engine"
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Max number of rounds of a scenario that does not specify it
+const DEFAULT_MAX_ROUNDS = 10000
+
+// Scenario that bundles everything required to reproduce an invasion: the map, the aliens, the rules and the seed
+type Scenario struct {
+	Map        string      `json:"map,omitempty"`        // Path of the world definition file, relative to the scenario file
+	World      string      `json:"world,omitempty"`      // Embedded world definition, used in place of the map file
+	Aliens     int         `json:"aliens"`               // Number of aliens deployed in random cities
+	Placements []Placement `json:"placements,omitempty"` // Aliens deployed in given cities, besides the random ones
+	Defenders  int         `json:"defenders,omitempty"`  // Number of human defenders deployed in random cities
+	Seed       *int64      `json:"seed,omitempty"`       // Seed of the random generator (a random one if nil)
+
+	Rules       Rules                  `json:"rules"`            // Rules of the invasion
+	Movement    Movement               `json:"movement"`         // Movement strategies of the agents
+	Termination Termination            `json:"termination"`      // Conditions that end the invasion
+	Events      []engine.ScriptedEvent `json:"events,omitempty"` // Events of the world scripted in advance
+
+	dir string // Directory the map path is relative to
+}
+
+// Alien deployed in a given city, with an optional identity
+type Placement struct {
+	City       string            `json:"city"`                 // City the alien is deployed in
+	Name       string            `json:"name,omitempty"`       // Alien name
+	Species    string            `json:"species,omitempty"`    // Alien species
+	Faction    string            `json:"faction,omitempty"`    // Faction the alien belongs to
+	HitPoints  int               `json:"hit_points,omitempty"` // Initial health (the default one if zero)
+	Strength   int               `json:"strength,omitempty"`   // Combat strength (the default one if zero)
+	Movement   string            `json:"movement,omitempty"`   // Movement strategy of the alien (e.g. "lazy:0.3")
+	Attributes map[string]string `json:"attributes,omitempty"` // Custom attributes
+}
+
+// Rules of the invasion, with the same meaning and format of the corresponding cli flags
+type Rules struct {
+	Combat          string   `json:"combat,omitempty"`           // Fight resolution (mutual destruction if empty)
+	DamageThreshold int      `json:"damage_threshold,omitempty"` // Damage above which a probabilistic fight destroys the city
+	Factions        string   `json:"factions,omitempty"`         // Faction shares the random aliens are split into (e.g. "red=0.5,blue=0.5")
+	Relations       string   `json:"relations,omitempty"`        // Relationships between factions (e.g. "red/blue=allies")
+	Wait            float64  `json:"wait,omitempty"`             // Probability of each alien to stay in its city for a round
+	HeadOn          bool     `json:"head_on,omitempty"`          // A boolean indicating if aliens traveling the same road in opposite directions fight
+	Rebuild         int      `json:"rebuild,omitempty"`          // Number of rounds after which an empty destroyed city gets rebuilt (never if zero)
+	PathLimit       int      `json:"path_limit,omitempty"`       // Max number of steps recorded in each alien path (100 if zero, unbounded if negative)
+	Waves           string   `json:"waves,omitempty"`            // Waves of aliens arriving during the invasion (e.g. "3:5@Foo/Bar,10:8")
+	SpawnRate       float64  `json:"spawn_rate,omitempty"`       // Number of aliens arriving each round
+	SpawnPoints     []string `json:"spawn_points,omitempty"`     // Cities the continuous spawn happens in (any city if empty)
+	SpawnUntil      int      `json:"spawn_until,omitempty"`      // Last round of the continuous spawn (no limit if zero)
+}
+
+// Movement strategies of the agents, in the format accepted by the movement cli flags
+type Movement struct {
+	Default   string            `json:"default,omitempty"`   // Default strategy (uniform if empty)
+	Factions  map[string]string `json:"factions,omitempty"`  // Strategies per faction
+	Defenders string            `json:"defenders,omitempty"` // Strategy of the defenders (the default one if empty)
+}
+
+// Conditions that end the invasion
+type Termination struct {
+	MaxRounds int      `json:"max_rounds,omitempty"` // Max number of rounds (DEFAULT_MAX_ROUNDS if zero)
+	Ignore    []string `json:"ignore,omitempty"`     // Ending conditions that do not stop the invasion (e.g. "all_aliens_stuck")
+}
+
+// Function that reads a scenario from a JSON file
+func Load(p string) (*Scenario, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", p, err)
+	}
+	s.dir = filepath.Dir(p)
+	return s, nil
+}
+
+// Function that reads a scenario in JSON format. Unknown fields are rejected, since they usually are typos.
+// A relative map path is resolved against the directory of the scenario file, when loaded with Load, and against
+// the working directory otherwise.
+func Parse(in io.Reader) (*Scenario, error) {
+	decoder := json.NewDecoder(in)
+	decoder.DisallowUnknownFields()
+
+	var s Scenario
+	if err := decoder.Decode(&s); err != nil {
+		return nil, err
+	}
+	if (s.Map == "") == (s.World == "") {
+		return nil, fmt.Errorf("exactly one of map and world must be set")
+	}
+	if s.Aliens < 0 || s.Defenders < 0 {
+		return nil, fmt.Errorf("invalid number of agents")
+	}
+	return &s, nil
+}
+
+// Method that reads the world definition of the scenario, either embedded or from the map file
+func (s *Scenario) worldDefinition() (io.ReadCloser, error) {
+	if s.World != "" {
+		return io.NopCloser(strings.NewReader(s.World)), nil
+	}
+
+	p := s.Map
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(s.dir, p)
+	}
+	return os.Open(p)
+}
+
+// Method that builds an engine ready to run the scenario.
+// The random generator gets seeded first, so that the same scenario always leads to the same invasion.
+func (s *Scenario) Engine() (*engine.Engine, error) {
+	if s.Seed != nil {
+		utils.Seed(*s.Seed)
+	}
+
+	in, err := s.worldDefinition()
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	w, err := world.Parse(in, s.Aliens)
+	if err != nil {
+		return nil, err
+	}
+	w.PathLimit = s.Rules.PathLimit
+
+	e := &engine.Engine{
+		MaxRuns:          s.Termination.MaxRounds,
+		World:            w,
+		WaitProbability:  s.Rules.Wait,
+		HeadOnCollisions: s.Rules.HeadOn,
+		RebuildAfter:     s.Rules.Rebuild,
+	}
+	if e.MaxRuns == 0 {
+		e.MaxRuns = DEFAULT_MAX_ROUNDS
+	}
+	if s.Rules.Wait < 0 || s.Rules.Wait > 1 {
+		return nil, fmt.Errorf("invalid wait probability: %f", s.Rules.Wait)
+	}
+
+	// Factions are assigned to the random aliens only, since the placed ones have their own
+	shares, err := world.ParseFactionShares(s.Rules.Factions)
+	if err != nil {
+		return nil, err
+	}
+	w.AssignFactions(shares)
+	if err := s.place(w); err != nil {
+		return nil, err
+	}
+	cities := w.SortedCityNames()
+	for i := 0; i < s.Defenders && len(cities) > 0; i++ {
+		if _, err := w.SpawnDefender(cities[utils.RandomInt(len(cities))]); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.assignStrategies(w); err != nil {
+		return nil, err
+	}
+
+	if e.Diplomacy, err = engine.ParseDiplomacy(s.Rules.Relations); err != nil {
+		return nil, err
+	}
+	combat := s.Rules.Combat
+	if combat == "" {
+		combat = engine.MUTUAL_COMBAT
+	}
+	if e.Combat, err = engine.NewCombatResolver(combat, s.Rules.DamageThreshold); err != nil {
+		return nil, err
+	}
+	if e.Spawn, err = s.spawnSchedule(w); err != nil {
+		return nil, err
+	}
+
+	// Events are checked when the scenario is loaded, and copied so that the same scenario can build several engines
+	for i := range s.Events {
+		ev := s.Events[i]
+		if err := ev.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid scripted %s event: %w", ev.Action, err)
+		}
+		e.Script = append(e.Script, &ev)
+	}
+
+	for _, name := range s.Termination.Ignore {
+		condition, err := engine.ParseEndingCondition(name)
+		if err != nil {
+			return nil, err
+		}
+		if e.Ignore == nil {
+			e.Ignore = make(map[engine.ExecutionStatus]bool)
+		}
+		e.Ignore[condition] = true
+	}
+	return e, nil
+}
+
+// Method that deploys the placed aliens, with their identity and movement strategy
+func (s *Scenario) place(w *world.World) error {
+	for _, p := range s.Placements {
+		al, err := w.SpawnAlien(p.City)
+		if err != nil {
+			return err
+		}
+		al.SetIdentity(alien.Identity{
+			Name:       p.Name,
+			Species:    p.Species,
+			Faction:    p.Faction,
+			Attributes: p.Attributes,
+			HitPoints:  p.HitPoints,
+			Strength:   p.Strength,
+		})
+		if p.Movement != "" {
+			strategy, err := world.ParseMovementStrategy(p.Movement)
+			if err != nil {
+				return err
+			}
+			w.SetStrategy(al.Id, strategy)
+		}
+	}
+	return nil
+}
+
+// Method that assigns the default, per faction and defenders movement strategies
+func (s *Scenario) assignStrategies(w *world.World) (err error) {
+	if s.Movement.Default != "" {
+		if w.DefaultStrategy, err = world.ParseMovementStrategy(s.Movement.Default); err != nil {
+			return err
+		}
+	}
+	if s.Movement.Defenders != "" {
+		if w.DefenderStrategy, err = world.ParseMovementStrategy(s.Movement.Defenders); err != nil {
+			return err
+		}
+	}
+	w.FactionStrategies = make(map[string]world.MovementStrategy, len(s.Movement.Factions))
+	for faction, spec := range s.Movement.Factions {
+		if w.FactionStrategies[faction], err = world.ParseMovementStrategy(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Method that builds the schedule of the aliens arriving during the invasion, if any
+func (s *Scenario) spawnSchedule(w *world.World) (*engine.SpawnSchedule, error) {
+	waves, err := engine.ParseWaves(s.Rules.Waves)
+	if err != nil {
+		return nil, err
+	}
+	if s.Rules.SpawnRate < 0 {
+		return nil, fmt.Errorf("invalid spawn rate: %f", s.Rules.SpawnRate)
+	}
+	if len(waves) == 0 && s.Rules.SpawnRate == 0 {
+		return nil, nil
+	}
+	schedule := &engine.SpawnSchedule{
+		Waves:  waves,
+		Rate:   s.Rules.SpawnRate,
+		Cities: s.Rules.SpawnPoints,
+		Until:  s.Rules.SpawnUntil,
+	}
+	if err := schedule.Validate(w); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
@@ -0,0 +1,146 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/engine"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+const testWorld = "A north=B east=C\nB south=A east=D\nC west=A north=D\nD west=B south=C\n"
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		input       string
+		expectError bool
+	}{
+		{`{"map": "world.txt", "aliens": 4}`, false},
+		{`{"world": "A north=B", "aliens": 2, "seed": 42, "termination": {"max_rounds": 10}}`, false},
+		{`{"aliens": 4}`, true},
+		{`{"map": "world.txt", "world": "A north=B", "aliens": 4}`, true},
+		{`{"map": "world.txt", "aliens": -1}`, true},
+		{`{"map": "world.txt", "alienz": 4}`, true},
+		{`{"map": "world.txt"`, true},
+	}
+
+	for _, test := range tests {
+		if _, err := Parse(strings.NewReader(test.input)); (err != nil) != test.expectError {
+			t.Errorf("%s: expected error %t, got %v", test.input, test.expectError, err)
+		}
+	}
+}
+
+func TestEngine(t *testing.T) {
+	var tests = []struct {
+		name        string
+		input       string
+		expectError bool
+	}{
+		{"combat", `{"world": "A", "aliens": 1, "rules": {"combat": "duel"}}`, true},
+		{"movement", `{"world": "A", "aliens": 1, "movement": {"factions": {"red": "teleport"}}}`, true},
+		{"placement", `{"world": "A", "aliens": 1, "placements": [{"city": "Z"}]}`, true},
+		{"termination", `{"world": "A", "aliens": 1, "termination": {"ignore": ["never"]}}`, true},
+		{"wait", `{"world": "A", "aliens": 1, "rules": {"wait": 2}}`, true},
+		{"map", `{"map": "missing.txt", "aliens": 1}`, true},
+		{"events", `{"world": "A", "aliens": 1, "events": [{"action": "flood"}]}`, true},
+		{"event direction", `{"world": "A", "aliens": 1, "events": [{"action": "close_road", "city": "A", "direction": "up"}]}`, true},
+		{"event road", `{"world": "A", "aliens": 1, "events": [{"action": "open_road", "city": "A", "direction": "north", "to": "A", "road": "w=x"}]}`, true},
+		{"event destination", `{"world": "A", "aliens": 1, "events": [{"action": "open_road", "city": "A", "direction": "north"}]}`, true},
+		{"event city", `{"world": "A", "aliens": 1, "events": [{"action": "teleport", "aliens": [0]}]}`, true},
+		{"event cities", `{"world": "A", "aliens": 1, "events": [{"action": "nuke"}]}`, true},
+		{"event aliens", `{"world": "A", "aliens": 1, "events": [{"action": "teleport", "city": "A"}]}`, true},
+		{"wave city", `{"world": "A", "aliens": 1, "rules": {"waves": "3:1@A/Z"}}`, true},
+		{"spawn point", `{"world": "A", "aliens": 1, "rules": {"spawn_rate": 0.5, "spawn_points": ["Z"]}}`, true},
+		{"storm duration", `{"world": "A", "aliens": 1, "events": [{"action": "storm", "cities": ["A"], "rounds": 0}]}`, true},
+		{"valid", `{"world": "A", "aliens": 1, "defenders": 1, "rules": {"waves": "3:1"}, "events": [{"action": "nuke", "when": {"round": 2}, "cities": ["A"]}]}`, false},
+	}
+
+	for _, test := range tests {
+		s, err := Parse(strings.NewReader(test.input))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.name, err)
+		}
+		if _, err := s.Engine(); (err != nil) != test.expectError {
+			t.Errorf("%s: expected error %t, got %v", test.name, test.expectError, err)
+		}
+	}
+}
+
+func TestPlacements(t *testing.T) {
+	s, err := Parse(strings.NewReader(`{
+		"world": "A north=B\nB south=A",
+		"aliens": 0,
+		"placements": [
+			{"city": "A", "name": "Zorg", "faction": "red", "strength": 7, "movement": "lazy:1"},
+			{"city": "B", "name": "Blip"}
+		],
+		"rules": {"factions": "blue=1"},
+		"termination": {"max_rounds": 5, "ignore": ["no_possible_fights"]}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := s.Engine()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zorg, blip := e.World.Aliens[0], e.World.Aliens[1]
+	if zorg.Name != "Zorg" || zorg.City.Name != "A" || zorg.Faction != "red" || zorg.Strength != 7 {
+		t.Errorf("Unexpected placed alien %+v", zorg)
+	}
+	if blip.Name != "Blip" || blip.City.Name != "B" || blip.Faction != "" {
+		t.Errorf("Unexpected placed alien %+v", blip)
+	}
+	if _, ok := e.World.StrategyOf(zorg).(world.LazyMovement); !ok {
+		t.Errorf("Expected the lazy strategy, got %T", e.World.StrategyOf(zorg))
+	}
+	if e.MaxRuns != 5 || !e.Ignore[engine.NO_POSSIBLE_FIGHTS] {
+		t.Errorf("Unexpected termination %d %v", e.MaxRuns, e.Ignore)
+	}
+}
+
+func TestReproducible(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "world.txt"), []byte(testWorld), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(dir, "scenario.json")
+	content := `{
+		"map": "world.txt",
+		"aliens": 6,
+		"defenders": 1,
+		"seed": 7,
+		"rules": {"combat": "probabilistic", "factions": "red=0.5,blue=0.5", "wait": 0.2},
+		"movement": {"default": "explorer", "factions": {"red": "hunter"}},
+		"termination": {"max_rounds": 50}
+	}`
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func() *engine.RunReport {
+		s, err := Load(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := s.Engine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := e.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	first, second := run(), run()
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected the same report twice, got\n%s\nand\n%s", first.Text(), second.Text())
+	}
+}
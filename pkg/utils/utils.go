@@ -2,12 +2,26 @@ package utils
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 )
 
+var (
+	rngMutex sync.Mutex
+	rng      = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Function that seeds the random generator, so that the following random values are reproducible
+func Seed(seed int64) {
+	rngMutex.Lock()
+	defer rngMutex.Unlock()
+	rng = rand.New(rand.NewSource(seed))
+}
+
 func RandomInt(max int) int {
-	rand.Seed(time.Now().UnixNano())
-	return rand.Intn(max)
+	rngMutex.Lock()
+	defer rngMutex.Unlock()
+	return rng.Intn(max)
 }
 
 func RandomBool() bool {
@@ -17,10 +31,75 @@ func RandomBool() bool {
 var chars = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 
 func RandomString(n int) string {
-	rand.Seed(time.Now().UnixNano())
+	return RandomStringFrom(chars, n)
+}
+
+// Function that builds a random string of n characters picked from the given alphabet
+func RandomStringFrom(alphabet []rune, n int) string {
 	b := make([]rune, n)
 	for i := range b {
-		b[i] = chars[rand.Intn(len(chars))]
+		b[i] = alphabet[RandomInt(len(alphabet))]
 	}
 	return string(b)
 }
+
+func RandomFloat() float64 {
+	rngMutex.Lock()
+	defer rngMutex.Unlock()
+	return rng.Float64()
+}
+
+// Function that returns a random 64 bits value, to be used as the seed of another generator
+func RandomUint64() uint64 {
+	rngMutex.Lock()
+	defer rngMutex.Unlock()
+	return rng.Uint64()
+}
+
+// Source of random values, so that the callers can provide their own generator (e.g. a *rand.Rand)
+type Random interface {
+	Float64() float64
+	Intn(n int) int
+}
+
+// Source of random values backed by the shared random generator
+var Shared Random = sharedRandom{}
+
+type sharedRandom struct{}
+
+func (sharedRandom) Float64() float64 { return RandomFloat() }
+func (sharedRandom) Intn(n int) int   { return RandomInt(n) }
+
+// Random generator (SplitMix64) with a tiny state, cheap enough to be instanciated for each alien at each round.
+// It is not safe for concurrent use.
+type SplitMix64 struct {
+	state uint64
+}
+
+// Function to instanciate a new SplitMix64, whose seed gets mixed with the given values (e.g. an alien id)
+func NewSplitMix64(seed uint64, values ...uint64) SplitMix64 {
+	r := SplitMix64{state: seed}
+	for _, v := range values {
+		r.state = r.Uint64() ^ v
+	}
+	return r
+}
+
+// Method that returns the next random 64 bits value
+func (r *SplitMix64) Uint64() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// Method that returns a random value in [0, 1)
+func (r *SplitMix64) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Method that returns a random value in [0, n)
+func (r *SplitMix64) Intn(n int) int {
+	return int(r.Uint64() % uint64(n))
+}
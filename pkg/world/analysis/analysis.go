@@ -0,0 +1,235 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Function that computes the strongly connected components of the surviving cities.
+// Aliens can travel between any two cities of the same component, in both directions.
+func StronglyConnectedComponents(w *world.World) [][]string {
+	return NewGraph(w).components()
+}
+
+func (g *Graph) components() [][]string {
+	components := make([][]string, 0)
+	for _, component := range g.Components() {
+		components = append(components, g.names(component))
+	}
+	sortComponents(components)
+	return components
+}
+
+// Function that finds the cities with no road leading to a surviving city.
+// Aliens landing in one of them inevitably get stuck.
+func Sinks(w *world.World) []string {
+	return NewGraph(w).sinks()
+}
+
+func (g *Graph) sinks() []string {
+	sinks := make([]int, 0)
+	for v, out := range g.Out {
+		if len(out) == 0 {
+			sinks = append(sinks, v)
+		}
+	}
+	return g.names(sinks)
+}
+
+// Function that finds the groups of cities no road leaves.
+// Aliens entering one of them can never leave it: a sink city is the smallest possible trap.
+func Traps(w *world.World) [][]string {
+	return NewGraph(w).traps()
+}
+
+func (g *Graph) traps() [][]string {
+	components := g.Components()
+
+	component := make([]int, len(g.Names))
+	for c, cities := range components {
+		for _, v := range cities {
+			component[v] = c
+		}
+	}
+
+	traps := make([][]string, 0)
+	for c, cities := range components {
+		closed := true
+		for _, v := range cities {
+			for _, w := range g.Out[v] {
+				if component[w] != c {
+					closed = false
+				}
+			}
+		}
+		if closed {
+			traps = append(traps, g.names(cities))
+		}
+	}
+	sortComponents(traps)
+	return traps
+}
+
+// Function that finds the cities no road leads to.
+// Only the aliens deployed there can ever visit them.
+func Unreachable(w *world.World) []string {
+	return NewGraph(w).unreachable()
+}
+
+func (g *Graph) unreachable() []string {
+	unreachable := make([]int, 0)
+	for v, in := range g.In {
+		if len(in) == 0 {
+			unreachable = append(unreachable, v)
+		}
+	}
+	return g.names(unreachable)
+}
+
+// Function that finds the structural chokepoints of the map: the cities whose destruction
+// would split a group of connected cities (considering roads regardless of their orientation)
+func ArticulationPoints(w *world.World) []string {
+	g := NewGraph(w)
+	return g.names(g.ArticulationPoints())
+}
+
+// Distribution of the number of roads leaving and entering the cities
+type Degrees struct {
+	Out map[int]int // A map that associates each number of outgoing roads to the number of cities having it
+	In  map[int]int // A map that associates each number of incoming roads to the number of cities having it
+}
+
+// Function that computes the degree distribution of the surviving cities
+func DegreeDistribution(w *world.World) Degrees {
+	return NewGraph(w).degrees()
+}
+
+func (g *Graph) degrees() Degrees {
+	degrees := Degrees{Out: make(map[int]int), In: make(map[int]int)}
+	for v := range g.Names {
+		degrees.Out[len(g.Out[v])]++
+		degrees.In[len(g.In[v])]++
+	}
+	return degrees
+}
+
+// Function that finds one of the shortest paths between two surviving cities.
+// The path includes both ends and an error is returned if no path exists.
+func ShortestPath(w *world.World, from string, to string) ([]string, error) {
+	g := NewGraph(w)
+	source, exists := g.Index[from]
+	if !exists {
+		return nil, &world.CityNotFoundError{Name: from}
+	}
+	target, exists := g.Index[to]
+	if !exists {
+		return nil, &world.CityNotFoundError{Name: to}
+	}
+
+	distances, previous := g.BFS(source)
+	if distances[target] == -1 {
+		return nil, fmt.Errorf("no path from %s to %s", from, to)
+	}
+
+	path := make([]string, distances[target]+1)
+	for v, i := target, len(path)-1; v != -1; v, i = previous[v], i-1 {
+		path[i] = g.Names[v]
+	}
+	return path, nil
+}
+
+// Function that sorts a list of components by decreasing size, then alphabetically
+func sortComponents(components [][]string) {
+	sort.Slice(components, func(i, j int) bool {
+		if len(components[i]) != len(components[j]) {
+			return len(components[i]) > len(components[j])
+		}
+		return components[i][0] < components[j][0]
+	})
+}
+
+// Report that sums up the analysis of a world map
+type Report struct {
+	Cities             int        // Number of surviving cities
+	Roads              int        // Number of roads between surviving cities
+	Components         [][]string // Strongly connected components
+	Sinks              []string   // Cities with no way out
+	Traps              [][]string // Groups of cities with no way out
+	Unreachable        []string   // Cities no road leads to
+	ArticulationPoints []string   // Cities whose destruction splits the map
+	Degrees            Degrees    // Degree distribution
+}
+
+// Function that runs every analysis on a world
+func Analyze(w *world.World) *Report {
+	g := NewGraph(w)
+	roads := 0
+	for _, out := range g.Out {
+		roads += len(out)
+	}
+
+	return &Report{
+		Cities:             len(g.Names),
+		Roads:              roads,
+		Components:         g.components(),
+		Sinks:              g.sinks(),
+		Traps:              g.traps(),
+		Unreachable:        g.unreachable(),
+		ArticulationPoints: g.names(g.ArticulationPoints()),
+		Degrees:            g.degrees(),
+	}
+}
+
+// Method that renders the report as human readable text
+func (r *Report) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Cities: %d\n", r.Cities)
+	fmt.Fprintf(&b, "Roads: %d\n", r.Roads)
+
+	largest := 0
+	if len(r.Components) > 0 {
+		largest = len(r.Components[0])
+	}
+	fmt.Fprintf(&b, "Strongly connected components: %d (largest has %d cities)\n", len(r.Components), largest)
+
+	fmt.Fprintf(&b, "Sink cities (aliens get stuck): %s\n", joinOrNone(r.Sinks))
+
+	traps := make([]string, 0, len(r.Traps))
+	for _, trap := range r.Traps {
+		if len(trap) > 1 {
+			traps = append(traps, "{"+strings.Join(trap, ", ")+"}")
+		}
+	}
+	fmt.Fprintf(&b, "Trap regions (aliens cannot leave): %s\n", joinOrNone(traps))
+	fmt.Fprintf(&b, "Unreachable cities: %s\n", joinOrNone(r.Unreachable))
+	fmt.Fprintf(&b, "Articulation points: %s\n", joinOrNone(r.ArticulationPoints))
+	fmt.Fprintf(&b, "Out-degree distribution: %s\n", formatDistribution(r.Degrees.Out))
+	fmt.Fprintf(&b, "In-degree distribution: %s", formatDistribution(r.Degrees.In))
+
+	return b.String()
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
+
+func formatDistribution(distribution map[int]int) string {
+	degrees := make([]int, 0, len(distribution))
+	for degree := range distribution {
+		degrees = append(degrees, degree)
+	}
+	sort.Ints(degrees)
+
+	items := make([]string, len(degrees))
+	for i, degree := range degrees {
+		items[i] = fmt.Sprintf("%d=%d", degree, distribution[degree])
+	}
+	return joinOrNone(items)
+}
@@ -0,0 +1,261 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+func parseWorld(t *testing.T, input string) *world.World {
+	w, err := world.Parse(strings.NewReader(input), 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return w
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	var tests = []struct {
+		input      string
+		destroyed  []string
+		components [][]string
+	}{
+		// A cycle and a one-way tail
+		{
+			input: `A north=B
+							B north=C
+							C north=A east=D`,
+			components: [][]string{{"A", "B", "C"}, {"D"}},
+		},
+		// Destroyed cities break the cycle
+		{
+			input: `A north=B
+							B north=C
+							C north=A east=D`,
+			destroyed:  []string{"B"},
+			components: [][]string{{"A"}, {"C"}, {"D"}},
+		},
+		// Two-way roads
+		{
+			input: `A east=B
+							B west=A east=C
+							C west=B`,
+			components: [][]string{{"A", "B", "C"}},
+		},
+	}
+
+	for _, test := range tests {
+		w := parseWorld(t, test.input)
+		if err := w.DestroyCities(test.destroyed); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := StronglyConnectedComponents(w); !reflect.DeepEqual(got, test.components) {
+			t.Errorf("Expected %v, got %v", test.components, got)
+		}
+	}
+}
+
+func TestTrapsAndSinks(t *testing.T) {
+	w := parseWorld(t, `A north=B east=E
+											B north=C
+											C south=B
+											E`)
+
+	if got := Sinks(w); !reflect.DeepEqual(got, []string{"E"}) {
+		t.Errorf("Expected sinks [E], got %v", got)
+	}
+
+	if got := Traps(w); !reflect.DeepEqual(got, [][]string{{"B", "C"}, {"E"}}) {
+		t.Errorf("Expected traps [[B C] [E]], got %v", got)
+	}
+
+	if got := Unreachable(w); !reflect.DeepEqual(got, []string{"A"}) {
+		t.Errorf("Expected unreachable [A], got %v", got)
+	}
+}
+
+func TestArticulationPoints(t *testing.T) {
+	var tests = []struct {
+		input  string
+		points []string
+	}{
+		// A chain: both inner cities are chokepoints
+		{
+			input: `A east=B
+							B east=C
+							C east=D`,
+			points: []string{"B", "C"},
+		},
+		// A square has no chokepoint
+		{
+			input: `A east=B south=C
+							B south=D
+							C east=D`,
+			points: []string{},
+		},
+		// A star: the center is the only chokepoint
+		{
+			input:  `X north=A east=B south=C west=D`,
+			points: []string{"X"},
+		},
+	}
+
+	for _, test := range tests {
+		if got := ArticulationPoints(parseWorld(t, test.input)); !reflect.DeepEqual(got, test.points) {
+			t.Errorf("Expected %v, got %v", test.points, got)
+		}
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	w := parseWorld(t, `A east=B south=C
+											B east=D
+											C east=E
+											E north=D
+											D`)
+
+	path, err := ShortestPath(w, "A", "D")
+	if err != nil || !reflect.DeepEqual(path, []string{"A", "B", "D"}) {
+		t.Errorf("Expected [A B D], got %v (%v)", path, err)
+	}
+
+	if _, err := ShortestPath(w, "D", "A"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+
+	// Destroyed cities cannot be traveled through
+	if err := w.DestroyCities([]string{"B"}); err != nil {
+		t.Fatal(err)
+	}
+	path, err = ShortestPath(w, "A", "D")
+	if err != nil || !reflect.DeepEqual(path, []string{"A", "C", "E", "D"}) {
+		t.Errorf("Expected [A C E D], got %v (%v)", path, err)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	r := Analyze(parseWorld(t, `A east=B
+															B west=A east=C`))
+
+	if r.Cities != 3 || r.Roads != 3 {
+		t.Errorf("Expected 3 cities and 3 roads, got %d and %d", r.Cities, r.Roads)
+	}
+
+	if !reflect.DeepEqual(r.Degrees.Out, map[int]int{0: 1, 1: 1, 2: 1}) {
+		t.Errorf("Unexpected out-degree distribution %v", r.Degrees.Out)
+	}
+
+	if !strings.Contains(r.String(), "Sink cities (aliens get stuck): C") {
+		t.Errorf("Expected C to be reported as sink, got %s", r)
+	}
+}
+
+func TestFightsPossible(t *testing.T) {
+	var tests = []struct {
+		input     string
+		aliens    map[int]string
+		destroyed []string
+		possible  bool
+	}{
+		// A lone alien cannot fight
+		{
+			input:    `A east=B`,
+			aliens:   map[int]string{0: "A"},
+			possible: false,
+		},
+		// Two aliens in the same city
+		{
+			input:    `A east=B`,
+			aliens:   map[int]string{0: "B", 1: "B"},
+			possible: true,
+		},
+		// One alien can reach the other one
+		{
+			input: `A east=B
+							B east=C`,
+			aliens:   map[int]string{0: "A", 1: "C"},
+			possible: true,
+		},
+		// Both aliens can reach the same city
+		{
+			input: `A east=B
+							C west=B`,
+			aliens:   map[int]string{0: "A", 1: "C"},
+			possible: true,
+		},
+		// Aliens on two one-way roads leading away from each other
+		{
+			input: `B east=C west=A
+							A west=X
+							C east=Y`,
+			aliens:   map[int]string{0: "A", 1: "C"},
+			possible: false,
+		},
+		// Aliens going around two separate loops, coming back to their own cities
+		{
+			input: `A east=B
+							B west=A
+							C east=D
+							D west=C`,
+			aliens:   map[int]string{0: "A", 1: "C"},
+			possible: false,
+		},
+		// The only city the aliens can share has been destroyed
+		{
+			input: `A east=B
+							C west=B`,
+			aliens:    map[int]string{0: "A", 1: "C"},
+			destroyed: []string{"B"},
+			possible:  false,
+		},
+	}
+
+	for _, test := range tests {
+		w := parseWorld(t, test.input)
+		for id, name := range test.aliens {
+			w.Aliens[id] = alien.NewAlien(id, w.Cities[name])
+		}
+		if err := w.DestroyCities(test.destroyed); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := FightsPossible(w, nil); got != test.possible {
+			t.Errorf("Expected %t, got %t for %v", test.possible, got, test.aliens)
+		}
+	}
+}
+
+func TestFightsPossibleFactions(t *testing.T) {
+	w := parseWorld(t, `A east=B
+											C west=B`)
+	a, c := alien.NewAlien(0, w.Cities["A"]), alien.NewAlien(1, w.Cities["C"])
+	w.Aliens[a.Id], w.Aliens[c.Id] = a, c
+
+	sameFaction := func(x, y *alien.Alien) bool {
+		return x.Faction == "" || x.Faction != y.Faction
+	}
+
+	// Allies can meet, but they never fight
+	a.Faction, c.Faction = "red", "red"
+	if FightsPossible(w, sameFaction) {
+		t.Error("Expected no possible fights between allies")
+	}
+
+	// Enemies can meet in B
+	c.Faction = "blue"
+	if !FightsPossible(w, sameFaction) {
+		t.Error("Expected possible fights between enemies")
+	}
+
+	// An enemy reaching B after an ally of the other alien
+	ally := alien.NewAlien(2, w.Cities["B"])
+	ally.Faction = "red"
+	w.Aliens[ally.Id] = ally
+	if !FightsPossible(w, sameFaction) {
+		t.Error("Expected possible fights between enemies")
+	}
+}
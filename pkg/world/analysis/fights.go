@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Function that decides whether two aliens may ever fight.
+// It must only depend on the aliens factions, where each alien without a faction is on its own.
+type HostilityFunc func(a, b *alien.Alien) bool
+
+// Function that considers any two aliens as hostile
+func AlwaysHostile(_, _ *alien.Alien) bool {
+	return true
+}
+
+// Function that returns the key of the group of aliens an alien behaves like
+func hostilityClass(a *alien.Alien) interface{} {
+	if a.IsDefender() {
+		return a.Kind
+	}
+	if a.Faction == "" {
+		return a.Id
+	}
+	return a.Faction
+}
+
+// Function that checks whether at least two living hostile aliens may still meet in the same city.
+// Two aliens can only meet in a city both of them can reach (or are already in), so it is enough
+// to check whether the sets of cities reachable by two hostile aliens overlap.
+//
+// A single breadth-first visit is performed, starting from all the aliens at the same time:
+// each city is claimed by the first alien of each faction reaching it, and as soon as an alien reaches
+// a city claimed by a hostile one, a meeting is possible. In this way, on dense maps the visit stops
+// as soon as the neighborhoods of two hostile aliens touch, while on sparse maps each city is visited
+// once per group of aliens that are not hostile to each other.
+//
+// The check is conservative: the aliens may never actually land in the same city at the same round.
+func FightsPossible(w *world.World, hostile HostilityFunc) bool {
+	if hostile == nil {
+		hostile = AlwaysHostile
+	}
+
+	type claim struct {
+		city  *city.City
+		alien *alien.Alien
+	}
+
+	var (
+		claims = make(map[string][]*alien.Alien)
+		queue  = make([]claim, 0)
+	)
+
+	// Function that makes an alien claim a city, returning whether a hostile alien already claimed it
+	visit := func(c *city.City, a *alien.Alien) bool {
+		for _, other := range claims[c.Name] {
+			if hostilityClass(a) == hostilityClass(other) {
+				// The alien itself or another one of the same group already continues the visit from here
+				return false
+			}
+			if hostile(a, other) {
+				return true
+			}
+		}
+		claims[c.Name] = append(claims[c.Name], a)
+		queue = append(queue, claim{c, a})
+		return false
+	}
+
+	ids := make([]int, 0, len(w.Aliens))
+	for id, al := range w.Aliens {
+		// Aliens in transit are considered as already arrived
+		if !al.Destroyed && al.NextCity() != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		al := w.Aliens[id]
+		if !al.NextCity().Destroyed {
+			if visit(al.NextCity(), al) {
+				return true
+			}
+			continue
+		}
+
+		// No fights happen among ruins, but aliens may still leave them through the surviving roads
+		// (the stuck ones are about to die instead)
+		for _, d := range world.Directions {
+			next, exists := w.Links[al.NextCity().Name][d]
+			if exists && !next.Destroyed && visit(next, al) {
+				return true
+			}
+		}
+	}
+
+	for ; len(queue) > 0; queue = queue[1:] {
+		current := queue[0]
+		for _, d := range world.Directions {
+			next, exists := w.Links[current.city.Name][d]
+			if !exists || next.Destroyed {
+				continue
+			}
+			if visit(next, current.alien) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,246 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Directed graph of the surviving cities, indexed by integers to keep the algorithms simple.
+// Destroyed cities are left out, since aliens can no longer travel to or through them.
+type Graph struct {
+	Names []string       // City names, sorted alphabetically
+	Index map[string]int // A map that associates each city name to its index
+	Out   [][]int        // Outgoing roads of each city
+	In    [][]int        // Incoming roads of each city
+}
+
+// Function that builds the graph of the surviving cities of a world
+func NewGraph(w *world.World) *Graph {
+	g := &Graph{
+		Names: make([]string, 0, len(w.Cities)),
+		Index: make(map[string]int, len(w.Cities)),
+	}
+
+	for name, c := range w.Cities {
+		if !c.Destroyed {
+			g.Names = append(g.Names, name)
+		}
+	}
+	sort.Strings(g.Names)
+
+	for i, name := range g.Names {
+		g.Index[name] = i
+	}
+
+	g.Out, g.In = make([][]int, len(g.Names)), make([][]int, len(g.Names))
+	for i, name := range g.Names {
+		for _, d := range world.Directions {
+			arrival, exists := w.Links[name][d]
+			if !exists || arrival.Destroyed {
+				continue
+			}
+			if j, exists := g.Index[arrival.Name]; exists {
+				g.Out[i] = append(g.Out[i], j)
+				g.In[j] = append(g.In[j], i)
+			}
+		}
+	}
+
+	return g
+}
+
+// Method that converts a list of city indexes into a sorted list of names
+func (g *Graph) names(ids []int) []string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = g.Names[id]
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Method that computes the strongly connected components with Tarjan's algorithm.
+// The recursion is unrolled on an explicit stack, so that huge maps do not overflow the goroutine stack.
+// Components are returned in reverse topological order: a component only has roads towards the previous ones.
+func (g *Graph) Components() [][]int {
+	type frame struct{ v, next int }
+
+	var (
+		n          = len(g.Names)
+		index      = make([]int, n)
+		low        = make([]int, n)
+		onStack    = make([]bool, n)
+		stack      = make([]int, 0)
+		counter    = 0
+		components = make([][]int, 0)
+	)
+
+	for i := range index {
+		index[i] = -1
+	}
+
+	for root := 0; root < n; root++ {
+		if index[root] != -1 {
+			continue
+		}
+
+		calls := []frame{{root, 0}}
+		index[root], low[root] = counter, counter
+		counter++
+		stack = append(stack, root)
+		onStack[root] = true
+
+		for len(calls) > 0 {
+			f := &calls[len(calls)-1]
+			if f.next < len(g.Out[f.v]) {
+				w := g.Out[f.v][f.next]
+				f.next++
+
+				if index[w] == -1 {
+					index[w], low[w] = counter, counter
+					counter++
+					stack = append(stack, w)
+					onStack[w] = true
+					calls = append(calls, frame{w, 0})
+				} else if onStack[w] {
+					low[f.v] = minInt(low[f.v], index[w])
+				}
+				continue
+			}
+
+			v := f.v
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				parent := calls[len(calls)-1].v
+				low[parent] = minInt(low[parent], low[v])
+			}
+
+			if low[v] == index[v] {
+				component := make([]int, 0)
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[w] = false
+					component = append(component, w)
+					if w == v {
+						break
+					}
+				}
+				components = append(components, component)
+			}
+		}
+	}
+
+	return components
+}
+
+// Method that finds the articulation points, considering roads regardless of their orientation.
+// An articulation point is a city whose destruction splits its group of connected cities in two or more.
+func (g *Graph) ArticulationPoints() []int {
+	type frame struct{ v, parent, next int }
+
+	var (
+		n        = len(g.Names)
+		adjacent = make([][]int, n)
+		disc     = make([]int, n)
+		low      = make([]int, n)
+		isPoint  = make([]bool, n)
+		counter  = 0
+	)
+
+	// Undirected adjacency lists, without duplicates nor self-loops
+	for v := 0; v < n; v++ {
+		seen := map[int]bool{v: true}
+		for _, neighbors := range [][]int{g.Out[v], g.In[v]} {
+			for _, w := range neighbors {
+				if !seen[w] {
+					seen[w] = true
+					adjacent[v] = append(adjacent[v], w)
+				}
+			}
+		}
+		disc[v] = -1
+	}
+
+	for root := 0; root < n; root++ {
+		if disc[root] != -1 {
+			continue
+		}
+
+		rootChildren := 0
+		calls := []frame{{root, -1, 0}}
+		disc[root], low[root] = counter, counter
+		counter++
+
+		for len(calls) > 0 {
+			f := &calls[len(calls)-1]
+			if f.next < len(adjacent[f.v]) {
+				w := adjacent[f.v][f.next]
+				f.next++
+
+				if disc[w] == -1 {
+					disc[w], low[w] = counter, counter
+					counter++
+					calls = append(calls, frame{w, f.v, 0})
+				} else if w != f.parent {
+					low[f.v] = minInt(low[f.v], disc[w])
+				}
+				continue
+			}
+
+			v, parent := f.v, f.parent
+			calls = calls[:len(calls)-1]
+			if parent == -1 {
+				continue
+			}
+
+			low[parent] = minInt(low[parent], low[v])
+			if parent == root {
+				rootChildren++
+			} else if low[v] >= disc[parent] {
+				isPoint[parent] = true
+			}
+		}
+
+		if rootChildren > 1 {
+			isPoint[root] = true
+		}
+	}
+
+	points := make([]int, 0)
+	for v, point := range isPoint {
+		if point {
+			points = append(points, v)
+		}
+	}
+	return points
+}
+
+// Method that computes the length of the shortest path from a city to every reachable one.
+// The returned slice also holds the previous city of each path (-1 for the source and unreachable cities).
+func (g *Graph) BFS(source int) (distances []int, previous []int) {
+	distances, previous = make([]int, len(g.Names)), make([]int, len(g.Names))
+	for i := range distances {
+		distances[i], previous[i] = -1, -1
+	}
+
+	distances[source] = 0
+	for queue := []int{source}; len(queue) > 0; queue = queue[1:] {
+		v := queue[0]
+		for _, w := range g.Out[v] {
+			if distances[w] == -1 {
+				distances[w], previous[w] = distances[v]+1, v
+				queue = append(queue, w)
+			}
+		}
+	}
+	return distances, previous
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
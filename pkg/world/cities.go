@@ -0,0 +1,70 @@
+package world
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+)
+
+// Function that parses a comma separated list of city attributes (e.g. pop=1000,def=2,hp=30) into a city
+func parseCityAttributes(c *city.City, s string) error {
+	for _, item := range strings.Split(s, ",") {
+		kv := strings.Split(item, "=")
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid city attribute: %s", item)
+		}
+
+		value, err := strconv.Atoi(kv[1])
+		if err != nil || value < 0 {
+			return fmt.Errorf("invalid city %s: %s", kv[0], kv[1])
+		}
+
+		switch kv[0] {
+		case "pop":
+			c.Population = value
+		case "def":
+			c.Defense = value
+		case "hp":
+			c.HitPoints = value
+		default:
+			return fmt.Errorf("unknown city attribute: %s", kv[0])
+		}
+	}
+	return nil
+}
+
+// Function that serializes the city attributes that are set, with the world definition format
+func cityAttributes(c *city.City) string {
+	attrs := make([]string, 0, 3)
+	if c.Population != 0 {
+		attrs = append(attrs, "pop="+strconv.Itoa(c.Population))
+	}
+	if c.Defense != 0 {
+		attrs = append(attrs, "def="+strconv.Itoa(c.Defense))
+	}
+	if c.HitPoints != 0 {
+		attrs = append(attrs, "hp="+strconv.Itoa(c.HitPoints))
+	}
+	return strings.Join(attrs, ",")
+}
+
+// Method that applies the damage of a fight to a city, destroying it if it falls.
+// It returns whether the city has been destroyed.
+func (w *World) DamageCity(name string, damage int) (bool, error) {
+	c, err := w.findCityPointer(name)
+	if err != nil {
+		return false, err
+	}
+	if c.Destroyed {
+		return true, nil
+	}
+	c, _ = w.writableCity(name)
+	w.saveOriginal(c)
+	if c.TakeDamage(damage) {
+		c.Destroyed = true
+		w.markDestroyed(c)
+	}
+	return c.Destroyed, nil
+}
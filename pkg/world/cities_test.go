@@ -0,0 +1,47 @@
+package world
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCityAttributes(t *testing.T) {
+	var tests = []struct {
+		input      string
+		parseError bool
+		wantedLine string
+	}{
+		{"A:pop=1000,def=2,hp=30 north=B", false, "A:pop=1000,def=2,hp=30 north=B"},
+		{"A:hp=3 north=B:w=2", false, "A:hp=3 north=B:w=2"},
+		{"A:hp=-3 north=B", true, ""},
+		{"A:walls=3 north=B", true, ""},
+		{"A:pop north=B", true, ""},
+	}
+
+	for _, test := range tests {
+		w, err := Parse(strings.NewReader(test.input), 0)
+		if (err != nil) != test.parseError {
+			t.Errorf("%s: expected error %t, got %v", test.input, test.parseError, err)
+			continue
+		}
+		if err == nil && w.String() != test.wantedLine {
+			t.Errorf("Expected %s, got %s", test.wantedLine, w.String())
+		}
+	}
+
+	// Attributes apply to cities already created as the target of a road
+	w, err := Parse(strings.NewReader("B south=A\nA:hp=3 north=B"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Cities["A"].HitPoints != 3 {
+		t.Errorf("Expected 3 hit points, got %d", w.Cities["A"].HitPoints)
+	}
+
+	if destroyed, err := w.DamageCity("A", 2); err != nil || destroyed {
+		t.Errorf("Expected A to resist, got destroyed %t (%v)", destroyed, err)
+	}
+	if destroyed, err := w.DamageCity("A", 2); err != nil || !destroyed || !w.Cities["A"].Destroyed {
+		t.Errorf("Expected A to fall, got destroyed %t (%v)", destroyed, err)
+	}
+}
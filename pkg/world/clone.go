@@ -0,0 +1,215 @@
+package world
+
+import (
+	"sync/atomic"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+)
+
+// Method that returns a deep copy of the world, sharing no mutable state with it.
+// Cities are copied once, and the links and the aliens of the copy point to the copied cities.
+func (w *World) Clone() *World {
+	c := w.shallowCopy()
+
+	cities := make(map[*city.City]*city.City, len(w.Cities))
+	for name, ct := range w.Cities {
+		copied := *ct
+		cities[ct] = &copied
+		c.Cities[name] = &copied
+	}
+	remap := func(ct *city.City) *city.City {
+		if copied, exists := cities[ct]; exists {
+			return copied
+		}
+		// Cities missing from the cities map (only possible when poking the maps directly) are copied as well
+		copied := *ct
+		cities[ct] = &copied
+		return &copied
+	}
+
+	for from, links := range w.Links {
+		c.Links[from] = make(map[Direction]*city.City, len(links))
+		for d, to := range links {
+			c.Links[from][d] = remap(to)
+		}
+	}
+	for from, roads := range w.Roads {
+		c.Roads[from] = make(map[Direction]Road, len(roads))
+		for d, road := range roads {
+			c.Roads[from][d] = road
+		}
+	}
+	for _, al := range c.Aliens {
+		remapAlien(al, remap)
+	}
+	return c
+}
+
+// Method that returns a copy-on-write copy of the world, which is cheaper than a deep copy when only a few
+// cities change afterwards. The cities and the roads are shared until either world changes them through its methods,
+// while the aliens are always copied. Once forked, the cities must not be changed by poking the maps directly.
+// Several goroutines can fork the same world at the same time, as long as none of them changes it meanwhile.
+func (w *World) Fork() *World {
+	// Both worlds stop owning the shared cities, so that neither can change them under the other: the cities owned
+	// by the parent were copied during an older generation
+	atomic.AddUint32(&w.generation, 1)
+
+	c := w.shallowCopy()
+	c.generation = 1
+	for name, ct := range w.Cities {
+		c.Cities[name] = ct
+	}
+	for from, links := range w.Links {
+		c.Links[from] = links
+	}
+	for from, roads := range w.Roads {
+		c.Roads[from] = roads
+	}
+	return c
+}
+
+// Method that copies everything but the cities, the links and the roads, whose maps are allocated empty
+func (w *World) shallowCopy() *World {
+	c := &World{
+		Cities:            make(CityMap, len(w.Cities)),
+		Links:             make(LinkMap, len(w.Links)),
+		Aliens:            make(AliensMap, len(w.Aliens)),
+		Roads:             make(RoadMap, len(w.Roads)),
+		Strategies:        make(map[int]MovementStrategy, len(w.Strategies)),
+		FactionStrategies: make(map[string]MovementStrategy, len(w.FactionStrategies)),
+		DefaultStrategy:   w.DefaultStrategy,
+		DefenderStrategy:  w.DefenderStrategy,
+		Round:             w.Round,
+		PathLimit:         w.PathLimit,
+		traversals:        make(map[roadKey]int, len(w.traversals)),
+		destroyedAt:       make(map[string]int, len(w.destroyedAt)),
+		originals:         make(map[string]city.City, len(w.originals)),
+		nextId:            w.nextId,
+		topology:          w.topology,
+	}
+	for id, al := range w.Aliens {
+		c.Aliens[id] = al.Copy()
+	}
+	for id, s := range w.Strategies {
+		c.Strategies[id] = s
+	}
+	for faction, s := range w.FactionStrategies {
+		c.FactionStrategies[faction] = s
+	}
+	for k, v := range w.traversals {
+		c.traversals[k] = v
+	}
+	for k, v := range w.destroyedAt {
+		c.destroyedAt[k] = v
+	}
+	for k, v := range w.originals {
+		c.originals[k] = v
+	}
+	return c
+}
+
+// Function that makes an alien point to the remapped cities
+func remapAlien(al *alien.Alien, remap func(*city.City) *city.City) {
+	if al.City != nil {
+		al.City = remap(al.City)
+	}
+	if al.Transit != nil {
+		al.Transit.From, al.Transit.To = remap(al.Transit.From), remap(al.Transit.To)
+	}
+}
+
+// Method that checks whether an item of the world is not shared with a forked world, so that it can be changed in
+// place, and returns the current generation
+func (w *World) owns(owned map[string]uint32, name string) (bool, uint32) {
+	generation := atomic.LoadUint32(&w.generation)
+	return generation == 0 || owned[name] == generation, generation
+}
+
+// Method that returns a city that can be changed, copying it first if it is shared with a forked world.
+// The links and the aliens pointing to the shared city get updated to point to the copy.
+func (w *World) writableCity(name string) (*city.City, error) {
+	shared, err := w.findCityPointer(name)
+	if err != nil {
+		return nil, err
+	}
+	owned, generation := w.owns(w.owned, name)
+	if owned {
+		return shared, nil
+	}
+
+	copied := *shared
+	w.Cities[name] = &copied
+	if w.owned == nil {
+		w.owned = make(map[string]uint32)
+	}
+	w.owned[name] = generation
+
+	remap := func(ct *city.City) *city.City {
+		if ct == shared {
+			return &copied
+		}
+		return ct
+	}
+	for from, links := range w.Links {
+		for d, to := range links {
+			if to == shared {
+				w.writableLinks(from)[d] = &copied
+			}
+		}
+	}
+	for _, al := range w.Aliens {
+		remapAlien(al, remap)
+	}
+	return &copied, nil
+}
+
+// Method that returns the links leaving a city that can be changed, copying them first if they are shared
+func (w *World) writableLinks(from string) map[Direction]*city.City {
+	if w.Links == nil {
+		w.Links = make(LinkMap)
+	}
+	owned, generation := w.owns(w.ownedLinks, from)
+	if owned {
+		if w.Links[from] == nil {
+			w.Links[from] = map[Direction]*city.City{}
+		}
+		return w.Links[from]
+	}
+
+	links := make(map[Direction]*city.City, len(w.Links[from]))
+	for d, to := range w.Links[from] {
+		links[d] = to
+	}
+	w.Links[from] = links
+	if w.ownedLinks == nil {
+		w.ownedLinks = make(map[string]uint32)
+	}
+	w.ownedLinks[from] = generation
+	return links
+}
+
+// Method that returns the attributes of the roads leaving a city that can be changed, copying them first if they are shared
+func (w *World) writableRoads(from string) map[Direction]Road {
+	if w.Roads == nil {
+		w.Roads = make(RoadMap)
+	}
+	owned, generation := w.owns(w.ownedRoads, from)
+	if owned {
+		if w.Roads[from] == nil {
+			w.Roads[from] = map[Direction]Road{}
+		}
+		return w.Roads[from]
+	}
+
+	roads := make(map[Direction]Road, len(w.Roads[from]))
+	for d, road := range w.Roads[from] {
+		roads[d] = road
+	}
+	w.Roads[from] = roads
+	if w.ownedRoads == nil {
+		w.ownedRoads = make(map[string]uint32)
+	}
+	w.ownedRoads[from] = generation
+	return roads
+}
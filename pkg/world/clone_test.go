@@ -0,0 +1,183 @@
+package world
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const cloneWorld = "Foo:pop=100,hp=40 north=Bar:w=3 west=Baz south=Qu-ux\nBar south=Foo west=Bee\nQu-ux north=Foo east=Bar:t=2\nBee east=Bar\n"
+
+// Function that serializes a world with its cities and roads sorted, since their order is not deterministic
+func sortedString(w *World) string {
+	lines := strings.Split(w.String(), "\n")
+	for i, line := range lines {
+		items := strings.Split(line, " ")
+		sort.Strings(items[1:])
+		lines[i] = strings.Join(items, " ")
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// Function that checks that the links and the aliens of a world only point to its own cities
+func checkPointers(t *testing.T, name string, w *World) {
+	for from, links := range w.Links {
+		for d, to := range links {
+			if w.Cities[to.Name] != to {
+				t.Errorf("%s: the road leaving %s toward %d points to a foreign city %s", name, from, d, to.Name)
+			}
+		}
+	}
+	for id, al := range w.Aliens {
+		if w.Cities[al.City.Name] != al.City {
+			t.Errorf("%s: alien %d points to a foreign city %s", name, id, al.City.Name)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	original, err := Parse(strings.NewReader(cloneWorld), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original.SetStrategy(0, LazyMovement{Probability: 1})
+	before := sortedString(original)
+
+	clone := original.Clone()
+	checkPointers(t, "clone", clone)
+	if sortedString(clone) != before {
+		t.Errorf("Expected the clone to match the original, got\n%s", clone.String())
+	}
+	for name, c := range clone.Cities {
+		if original.Cities[name] == c {
+			t.Errorf("Expected city %s to be copied", name)
+		}
+	}
+	if _, ok := clone.StrategyOf(clone.Aliens[0]).(LazyMovement); !ok {
+		t.Errorf("Expected the strategies to be copied")
+	}
+
+	// Changing the clone leaves the original untouched
+	if err := clone.DestroyCities([]string{"Foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := clone.AddRoad("Bee", South, "Qu-ux", DefaultRoad()); err != nil {
+		t.Fatal(err)
+	}
+	if err := clone.TeleportAlien(1, "Bee"); err != nil {
+		t.Fatal(err)
+	}
+	if sortedString(original) != before || original.Cities["Foo"].Destroyed || len(original.Aliens[1].Path) != 1 {
+		t.Errorf("Expected the original to be untouched, got\n%s", original.String())
+	}
+	checkPointers(t, "clone", clone)
+}
+
+func TestFork(t *testing.T) {
+	original, err := Parse(strings.NewReader(cloneWorld), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Foo", "Baz", "Qu-ux"} {
+		if _, err := original.SpawnAlien(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := sortedString(original)
+
+	fork := original.Fork()
+	for name, c := range fork.Cities {
+		if original.Cities[name] != c {
+			t.Errorf("Expected city %s to be shared until changed", name)
+		}
+	}
+
+	// The fork copies the cities it changes, along with the links and the aliens pointing to them
+	if _, err := fork.DamageCity("Foo", 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := fork.DestroyCities([]string{"Bar"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fork.RemoveRoad("Foo", North); err != nil {
+		t.Fatal(err)
+	}
+	if fork.Cities["Foo"] == original.Cities["Foo"] || fork.Cities["Baz"] != original.Cities["Baz"] {
+		t.Errorf("Expected only the changed cities to be copied")
+	}
+	checkPointers(t, "fork", fork)
+	if sortedString(original) != before || original.Cities["Foo"].HitPoints != 40 || original.Cities["Bar"].Destroyed {
+		t.Errorf("Expected the original to be untouched, got\n%s", original.String())
+	}
+
+	// The original copies the cities it changes as well, so the fork is not affected
+	forkBefore := sortedString(fork)
+	if err := original.DestroyCities([]string{"Qu-ux"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := original.AddRoad("Bee", South, "Qu-ux", Road{Weight: 1, Time: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := original.RenameCity("Baz", "Bazz"); err != nil {
+		t.Fatal(err)
+	}
+	checkPointers(t, "original", original)
+	if sortedString(fork) != forkBefore || fork.Cities["Qu-ux"].Destroyed || fork.Cities["Baz"].Name != "Baz" {
+		t.Errorf("Expected the fork to be untouched, got\n%s", fork.String())
+	}
+
+	// Rebuilding restores the fork own copy only
+	fork.Round = 5
+	if rebuilt := fork.RebuildCities(1); len(rebuilt) == 0 || !original.Cities["Qu-ux"].Destroyed {
+		t.Errorf("Unexpected rebuild %v", rebuilt)
+	}
+}
+
+func TestConcurrentForks(t *testing.T) {
+	original, err := Parse(strings.NewReader(cloneWorld), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := sortedString(original)
+
+	// Parallel experiments fork the same world on their own, and change their fork only
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fork := original.Fork()
+			if _, err := fork.DamageCity("Foo", 10*i); err != nil {
+				t.Error(err)
+			}
+			if err := fork.RemoveRoad("Bar", West); err != nil {
+				t.Error(err)
+			}
+			if err := fork.DestroyCities([]string{"Qu-ux"}); err != nil {
+				t.Error(err)
+			}
+			for id := range fork.Aliens {
+				if _, err := fork.RandomlyMove(id); err != nil {
+					t.Error(err)
+				}
+			}
+			checkPointers(t, "fork", fork)
+		}(i)
+	}
+	wg.Wait()
+
+	if sortedString(original) != before || original.Cities["Foo"].HitPoints != 40 || original.Cities["Qu-ux"].Destroyed {
+		t.Errorf("Expected the original to be untouched, got\n%s", original.String())
+	}
+
+	// The original does not change the cities it shares with the forks in place anymore
+	shared := original.Cities["Foo"]
+	if _, err := original.DamageCity("Foo", 10); err != nil {
+		t.Fatal(err)
+	}
+	if original.Cities["Foo"] == shared || shared.HitPoints != 40 {
+		t.Errorf("Expected the original to copy the shared city before changing it")
+	}
+}
@@ -0,0 +1,24 @@
+package compact
+
+// Set of dense identification numbers, stored as one bit each
+type Bitset []uint64
+
+// Function to instanciate a new empty Bitset able to hold n elements
+func NewBitset(n int) Bitset {
+	return make(Bitset, (n+63)/64)
+}
+
+// Method that checks whether an element is in the set
+func (b Bitset) Has(i int32) bool {
+	return b[i>>6]&(1<<(uint(i)&63)) != 0
+}
+
+// Method that adds an element to the set
+func (b Bitset) Set(i int32) {
+	b[i>>6] |= 1 << (uint(i) & 63)
+}
+
+// Method that removes an element from the set
+func (b Bitset) Clear(i int32) {
+	b[i>>6] &^= 1 << (uint(i) & 63)
+}
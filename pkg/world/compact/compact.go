@@ -0,0 +1,330 @@
+package compact
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/AzraelSec/mad-aliens/pkg/engine"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// Identification number of a city that does not exist, used for the missing roads
+const NO_CITY = -1
+
+// Compact representation of a world, where cities and aliens are identified by dense integer ids.
+// It only supports the default rules: aliens move uniformly (proportionally to the roads weight), roads take a round
+// and have no capacity, and two aliens landing in the same city destroy each other and the city.
+// Moving the aliens never allocates, so it scales to millions of cities and hundreds of thousands of aliens.
+type World struct {
+	Round int // Number of rounds already performed
+
+	names   []string         // City names, indexed by city id
+	cityIds map[string]int32 // City ids, indexed by city name
+	links   []int32          // City reached from each city in each direction (NO_CITY if none), len(world.Directions) per city
+	weights []float64        // Weight of each road, with the same layout of the links (nil while all the roads have the default one)
+
+	destroyed  Bitset // Destroyed cities
+	nDestroyed int    // Number of destroyed cities
+
+	alienIds  []int   // Original identification number of each alien, indexed by alien id
+	positions []int32 // City each alien is located in, indexed by alien id
+	dead      Bitset  // Destroyed aliens
+	stuck     Bitset  // Aliens that could not move the last time they tried
+	alive     int     // Number of alive aliens
+	nStuck    int     // Number of alive stuck aliens
+
+	visitedAt []int32 // Last round each city has been landed in (-1 if never, or if destroyed)
+	occupant  []int32 // First alien that landed in each city during the round it was visited at
+
+	fights fightsCheck // State of the possible fights check, reused across the rounds
+}
+
+// Function to instanciate a new World with the given cities, without any road or alien
+func New(names []string) *World {
+	w := &World{
+		names:     names,
+		cityIds:   make(map[string]int32, len(names)),
+		links:     make([]int32, len(names)*len(world.Directions)),
+		destroyed: NewBitset(len(names)),
+		dead:      NewBitset(0),
+		stuck:     NewBitset(0),
+		visitedAt: make([]int32, len(names)),
+		occupant:  make([]int32, len(names)),
+	}
+	for i, name := range names {
+		w.cityIds[name] = int32(i)
+		w.visitedAt[i] = -1
+	}
+	for i := range w.links {
+		w.links[i] = NO_CITY
+	}
+	return w
+}
+
+// Function that builds the compact representation of a world.
+// An error is returned if the world uses a feature the compact representation does not support.
+func FromWorld(w *world.World) (*World, error) {
+	if len(w.Strategies) > 0 || len(w.FactionStrategies) > 0 || w.DefenderStrategy != nil {
+		return nil, fmt.Errorf("movement strategies are not supported")
+	}
+	if _, uniform := w.DefaultStrategy.(world.UniformMovement); w.DefaultStrategy != nil && !uniform {
+		return nil, fmt.Errorf("movement strategies are not supported")
+	}
+
+	c := New(w.SortedCityNames())
+	for i, name := range c.names {
+		city := w.Cities[name]
+		if city.Defense != 0 || city.HitPoints != 0 {
+			return nil, fmt.Errorf("city attributes are not supported: %s", name)
+		}
+		if city.Destroyed {
+			c.destroyed.Set(int32(i))
+			c.nDestroyed++
+		}
+		for d, to := range w.Links[name] {
+			road := w.Road(name, d)
+			if road.Time != world.DefaultRoadTime || road.Capacity != 0 {
+				return nil, fmt.Errorf("road travel times and capacities are not supported: %s", name)
+			}
+			target, exists := c.cityIds[to.Name]
+			if !exists {
+				return nil, &world.CityNotFoundError{Name: to.Name}
+			}
+			c.Link(int32(i), d, target, road.Weight)
+		}
+	}
+
+	for _, id := range w.SortedAlienIds() {
+		al := w.Aliens[id]
+		switch {
+		case al.IsDefender():
+			return nil, fmt.Errorf("defenders are not supported")
+		case al.Faction != "":
+			return nil, fmt.Errorf("factions are not supported")
+		case al.InTransit():
+			return nil, fmt.Errorf("aliens in transit are not supported")
+		}
+		a := c.Deploy(c.cityIds[al.City.Name])
+		c.alienIds[a] = id
+		if al.Destroyed {
+			c.dead.Set(a)
+			c.alive--
+		} else if al.Stuck {
+			c.stuck.Set(a)
+			c.nStuck++
+		}
+	}
+	c.Round = w.Round
+	return c, nil
+}
+
+// Function that builds the compact representation of the world of an engine.
+// An error is returned if the engine uses a rule the compact representation does not support, besides the world
+// features FromWorld rejects.
+func FromEngine(e *engine.Engine) (*World, error) {
+	if _, mutual := e.Combat.(engine.MutualDestruction); e.Combat != nil && !mutual {
+		return nil, fmt.Errorf("combat modes other than mutual destruction are not supported")
+	}
+	switch {
+	case e.WaitProbability != 0:
+		return nil, fmt.Errorf("waiting aliens are not supported")
+	case e.RebuildAfter != 0:
+		return nil, fmt.Errorf("rebuilding cities is not supported")
+	case e.Spawn.Pending(e.Runs):
+		return nil, fmt.Errorf("spawning aliens is not supported")
+	case len(e.Script) > 0:
+		return nil, fmt.Errorf("scripted events are not supported")
+	case e.HeadOnCollisions:
+		return nil, fmt.Errorf("head-on collisions are not supported")
+	case e.Workers != 0:
+		return nil, fmt.Errorf("parallel mode is not supported")
+	}
+	for status, ignored := range e.Ignore {
+		if ignored {
+			return nil, fmt.Errorf("ignoring ending conditions is not supported: %s", engine.ExecStatusString(status))
+		}
+	}
+
+	w, err := FromWorld(e.World)
+	if err != nil {
+		return nil, err
+	}
+	w.Round = e.Runs
+	return w, nil
+}
+
+// Method that adds a road leaving a city in a direction, replacing the existing one, if any
+func (w *World) Link(from int32, d world.Direction, to int32, weight float64) {
+	slot := int(from)*len(world.Directions) + d
+	w.links[slot] = to
+	if weight != world.DefaultRoadWeight && w.weights == nil {
+		w.weights = make([]float64, len(w.links))
+		for i := range w.weights {
+			w.weights[i] = world.DefaultRoadWeight
+		}
+	}
+	if w.weights != nil {
+		w.weights[slot] = weight
+	}
+	w.fights.componentsAt = -1
+}
+
+// Method that deploys a new alien in a city, returning its id
+func (w *World) Deploy(city int32) int32 {
+	id := int32(len(w.positions))
+	w.positions = append(w.positions, city)
+	w.alienIds = append(w.alienIds, int(id))
+	if int(id) >= len(w.dead)*64 {
+		w.dead = append(w.dead, 0)
+		w.stuck = append(w.stuck, 0)
+	}
+	w.alive++
+	return id
+}
+
+// Method that picks the next city of an alien leaving a city, or NO_CITY if no road leads to a surviving city
+func (w *World) chooseMove(city int32, rng *rand.Rand) int32 {
+	var (
+		candidates [4]int32
+		weights    [4]float64
+		n          = 0
+		total      = 0.0
+	)
+	base := int(city) * len(world.Directions)
+	for d := range world.Directions {
+		to := w.links[base+d]
+		if to == NO_CITY || w.destroyed.Has(to) {
+			continue
+		}
+		candidates[n] = to
+		weights[n] = world.DefaultRoadWeight
+		if w.weights != nil {
+			weights[n] = w.weights[base+d]
+		}
+		total += weights[n]
+		n++
+	}
+
+	switch {
+	case n == 0:
+		return NO_CITY
+	case w.weights == nil:
+		return candidates[rng.Intn(n)]
+	}
+	roll := rng.Float64() * total
+	for i := 0; i < n-1; i++ {
+		if roll < weights[i] {
+			return candidates[i]
+		}
+		roll -= weights[i]
+	}
+	return candidates[n-1]
+}
+
+// Method that performs a single round: aliens move in increasing order of id, and the ones landing in a city
+// already landed in during the round destroy it, along with the alien that landed first
+func (w *World) Tick(rng *rand.Rand) {
+	round := int32(w.Round)
+	for a := int32(0); int(a) < len(w.positions); a++ {
+		if w.dead.Has(a) {
+			continue
+		}
+
+		city := w.positions[a]
+		if next := w.chooseMove(city, rng); next != NO_CITY {
+			if w.stuck.Has(a) {
+				w.stuck.Clear(a)
+				w.nStuck--
+			}
+			city = next
+			w.positions[a] = city
+		} else {
+			if !w.stuck.Has(a) {
+				w.stuck.Set(a)
+				w.nStuck++
+			}
+			// Stuck aliens in a city destroyed by others die with it
+			if w.destroyed.Has(city) {
+				w.kill(a)
+				continue
+			}
+		}
+
+		if w.visitedAt[city] != round {
+			w.visitedAt[city], w.occupant[city] = round, a
+			continue
+		}
+		w.kill(a)
+		w.kill(w.occupant[city])
+		w.destroyed.Set(city)
+		w.nDestroyed++
+		w.visitedAt[city] = -1
+	}
+	w.Round++
+}
+
+// Method that destroys an alien
+func (w *World) kill(a int32) {
+	w.dead.Set(a)
+	w.alive--
+	if w.stuck.Has(a) {
+		w.nStuck--
+	}
+}
+
+// Method that performs rounds until the same ending conditions of the engine hold: all the aliens are dead or stuck,
+// no two aliens can meet anymore, or the max number of rounds is reached.
+// It returns the number of rounds performed, along with the ending condition.
+func (w *World) Run(maxRounds int, rng *rand.Rand) (int, engine.ExecutionStatus) {
+	start := w.Round
+	for {
+		switch {
+		case w.alive == 0:
+			return w.Round - start, engine.NO_ALIENS_LEFT
+		case w.nStuck == w.alive:
+			return w.Round - start, engine.ALL_ALIENS_STUCK
+		case !w.FightsPossible():
+			return w.Round - start, engine.NO_POSSIBLE_FIGHTS
+		case w.Round-start >= maxRounds:
+			return w.Round - start, engine.MAX_ROUND_REACHED
+		}
+		w.Tick(rng)
+	}
+}
+
+// Method that returns the world statistics
+func (w *World) Stats() world.Stats {
+	return world.Stats{
+		AliveAliens:     w.alive,
+		StuckAliens:     w.nStuck,
+		DestroyedAliens: len(w.positions) - w.alive,
+		SurvivingCities: len(w.names) - w.nDestroyed,
+		DestroyedCities: w.nDestroyed,
+	}
+}
+
+// Method that returns the name of a city
+func (w *World) CityName(city int32) string {
+	return w.names[city]
+}
+
+// Method that returns the id of a city, if it exists
+func (w *World) CityId(name string) (int32, bool) {
+	id, exists := w.cityIds[name]
+	return id, exists
+}
+
+// Method that returns the original identification numbers of the aliens, indexed by alien id
+func (w *World) AlienIds() []int {
+	return w.alienIds
+}
+
+// Method that returns the city an alien is located in, and whether it is still alive
+func (w *World) Position(a int32) (int32, bool) {
+	return w.positions[a], !w.dead.Has(a)
+}
+
+// Method that checks whether a city has been destroyed
+func (w *World) Destroyed(city int32) bool {
+	return w.destroyed.Has(city)
+}
@@ -0,0 +1,397 @@
+package compact
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/engine"
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+	"github.com/AzraelSec/mad-aliens/pkg/world/analysis"
+)
+
+func TestBitset(t *testing.T) {
+	b := NewBitset(130)
+	for _, i := range []int32{0, 63, 64, 129} {
+		b.Set(i)
+	}
+	b.Clear(63)
+	for i := int32(0); i < 130; i++ {
+		if wanted := i == 0 || i == 64 || i == 129; b.Has(i) != wanted {
+			t.Errorf("Expected %d in the set %t", i, wanted)
+		}
+	}
+}
+
+func TestTick(t *testing.T) {
+	// A and C both lead to B, while D has no roads
+	w := New([]string{"A", "B", "C", "D"})
+	w.Link(0, world.North, 1, world.DefaultRoadWeight)
+	w.Link(2, world.West, 1, world.DefaultRoadWeight)
+	for _, c := range []int32{0, 2, 3} {
+		w.Deploy(c)
+	}
+
+	if rounds, status := w.Run(10, rand.New(rand.NewSource(1))); rounds != 1 || status != engine.ALL_ALIENS_STUCK {
+		t.Errorf("Expected all the aliens stuck after 1 round, got status %d after %d rounds", status, rounds)
+	}
+	wanted := world.Stats{AliveAliens: 1, StuckAliens: 1, DestroyedAliens: 2, SurvivingCities: 3, DestroyedCities: 1}
+	if s := w.Stats(); s != wanted {
+		t.Errorf("Expected stats %+v, got %+v", wanted, s)
+	}
+	if !w.Destroyed(1) {
+		t.Errorf("Expected B to be destroyed")
+	}
+	if city, alive := w.Position(2); !alive || w.CityName(city) != "D" {
+		t.Errorf("Expected alien 2 alive in D, got %s (%t)", w.CityName(city), alive)
+	}
+}
+
+// Function that builds a random world where each city has at most one road, so that the movement is deterministic
+func deterministicWorld(rng *rand.Rand, nCities, nAliens int) *world.World {
+	cities, names := make(world.CityMap), make([]string, nCities)
+	for i := range names {
+		names[i] = fmt.Sprintf("C%d", i)
+		cities[names[i]] = city.NewCity(names[i])
+	}
+	links := make(world.LinkMap)
+	for i, name := range names {
+		if to := rng.Intn(nCities); to != i && rng.Float64() < 0.9 {
+			links[name] = map[world.Direction]*city.City{world.Directions[rng.Intn(4)]: cities[names[to]]}
+		}
+	}
+	aliens := make(world.AliensMap)
+	for id := 0; id < nAliens; id++ {
+		aliens[id] = alien.NewAlien(id, cities[names[rng.Intn(nCities)]])
+	}
+	return world.NewWorld(cities, links, aliens)
+}
+
+func TestMatchesEngine(t *testing.T) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(out)
+
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 50; i++ {
+		w := deterministicWorld(rng, rng.Intn(30)+1, rng.Intn(20)+1)
+		c, err := FromWorld(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e := &engine.Engine{World: w, MaxRuns: 50}
+		report, err := e.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rounds, status := c.Run(50, rng); rounds != report.Rounds || status != report.Status {
+			t.Errorf("Expected status %d after %d rounds, got status %d after %d rounds", report.Status, report.Rounds, status, rounds)
+		}
+		if c.Stats() != w.Stats() {
+			t.Errorf("Expected stats %+v, got %+v", w.Stats(), c.Stats())
+		}
+		for a, id := range c.AlienIds() {
+			city, alive := c.Position(int32(a))
+			if al := w.Aliens[id]; alive == al.Destroyed || alive && c.CityName(city) != al.City.Name {
+				t.Errorf("Expected alien %d in %s (destroyed %t), got %s (alive %t)", id, al.City.Name, al.Destroyed, c.CityName(city), alive)
+			}
+		}
+	}
+}
+
+// Function that rebuilds a world from the state of a compact one, keeping only the alive aliens
+func fromCompact(c *World) *world.World {
+	cities, links, aliens := make(world.CityMap), make(world.LinkMap), make(world.AliensMap)
+	for i, name := range c.names {
+		cities[name] = city.NewCity(name)
+		cities[name].Destroyed = c.Destroyed(int32(i))
+	}
+	for i, name := range c.names {
+		for _, d := range world.Directions {
+			if to := c.links[i*len(world.Directions)+d]; to != NO_CITY {
+				if links[name] == nil {
+					links[name] = make(map[world.Direction]*city.City)
+				}
+				links[name][d] = cities[c.names[to]]
+			}
+		}
+	}
+	for a, id := range c.AlienIds() {
+		if position, alive := c.Position(int32(a)); alive {
+			aliens[id] = alien.NewAlien(id, cities[c.names[position]])
+		}
+	}
+	return world.NewWorld(cities, links, aliens)
+}
+
+func TestFightsPossible(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 50; i++ {
+		// Random roads, so that aliens wander around cycles and sometimes leave them
+		n := rng.Intn(40) + 2
+		names := make([]string, n)
+		for j := range names {
+			names[j] = fmt.Sprintf("C%d", j)
+		}
+		c := New(names)
+		for from := int32(0); int(from) < n; from++ {
+			for _, d := range world.Directions {
+				if rng.Float64() < 0.35 {
+					c.Link(from, d, int32(rng.Intn(n)), world.DefaultRoadWeight)
+				}
+			}
+		}
+		for a := rng.Intn(6) + 1; a > 0; a-- {
+			c.Deploy(int32(rng.Intn(n)))
+		}
+
+		// Every round, the check agrees with the analysis of the same world
+		for round := 0; round < 100; round++ {
+			wanted := analysis.FightsPossible(fromCompact(c), nil)
+			if got := c.FightsPossible(); got != wanted {
+				t.Fatalf("world %d, round %d: expected fights possible %t, got %t", i, round, wanted, got)
+			}
+			if !wanted || c.alive == 0 {
+				break
+			}
+			c.Tick(rng)
+		}
+	}
+}
+
+func TestChooseMoveDistribution(t *testing.T) {
+	// X has weighted roads toward three cities, and a heavier one toward a destroyed city
+	x, destroyed := city.NewCity("X"), city.NewCity("D")
+	destroyed.Destroyed = true
+	cities := world.CityMap{"X": x, "D": destroyed, "A": city.NewCity("A"), "B": city.NewCity("B"), "C": city.NewCity("C")}
+	w := world.NewWorld(cities, world.LinkMap{
+		"X": {world.North: cities["A"], world.South: cities["B"], world.East: cities["C"], world.West: destroyed},
+	}, world.AliensMap{0: alien.NewAlien(0, x)})
+	w.Roads = world.RoadMap{"X": {
+		world.North: {Weight: 1, Time: 1},
+		world.South: {Weight: 2, Time: 1},
+		world.East:  {Weight: 5, Time: 1},
+		world.West:  {Weight: 8, Time: 1},
+	}}
+	c, err := FromWorld(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, _ := c.CityId("X")
+
+	// Both the compact world and the uniform movement pick each road proportionally to its weight
+	const draws = 80000
+	compactCounts, worldCounts := make(map[string]int), make(map[string]int)
+	compactRng, worldRng := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(2))
+	for i := 0; i < draws; i++ {
+		compactCounts[c.CityName(c.chooseMove(from, compactRng))]++
+		worldCounts[world.UniformMovement{}.Choose(w, w.Aliens[0], w.AvailableMoves("X"), worldRng).City.Name]++
+	}
+	for name, weight := range map[string]float64{"A": 1, "B": 2, "C": 5, "D": 0} {
+		wanted := weight / 8
+		for mode, counts := range map[string]map[string]int{"compact": compactCounts, "world": worldCounts} {
+			if got := float64(counts[name]) / draws; math.Abs(got-wanted) > 0.01 {
+				t.Errorf("%s: expected %s to be picked with frequency %.3f, got %.3f", mode, name, wanted, got)
+			}
+		}
+	}
+}
+
+func TestFromWorld(t *testing.T) {
+	var tests = []struct {
+		name  string
+		setup func(w *world.World)
+	}{
+		{"strategy", func(w *world.World) { w.DefaultStrategy = world.HunterMovement{} }},
+		{"alien strategy", func(w *world.World) { w.SetStrategy(0, world.UniformMovement{}) }},
+		{"city attributes", func(w *world.World) { w.Cities["A"].HitPoints = 10 }},
+		{"road time", func(w *world.World) { w.Roads = world.RoadMap{"A": {world.North: {Weight: 1, Time: 2}}} }},
+		{"faction", func(w *world.World) { w.Aliens[0].Faction = "red" }},
+		{"defender", func(w *world.World) { w.Aliens[0].Kind = alien.DEFENDER }},
+	}
+
+	for _, test := range tests {
+		a, b := city.NewCity("A"), city.NewCity("B")
+		w := world.NewWorld(world.CityMap{"A": a, "B": b}, world.LinkMap{"A": {world.North: b}}, world.AliensMap{0: alien.NewAlien(0, a)})
+		if _, err := FromWorld(w); err != nil {
+			t.Fatalf("%s: expected no error before the setup, got %v", test.name, err)
+		}
+		test.setup(w)
+		if _, err := FromWorld(w); err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
+
+func TestFromEngine(t *testing.T) {
+	var tests = []struct {
+		name  string
+		setup func(e *engine.Engine)
+	}{
+		{"combat", func(e *engine.Engine) { e.Combat = engine.ProbabilisticCombat{} }},
+		{"wait", func(e *engine.Engine) { e.WaitProbability = 0.5 }},
+		{"rebuild", func(e *engine.Engine) { e.RebuildAfter = 2 }},
+		{"spawn", func(e *engine.Engine) { e.Spawn = &engine.SpawnSchedule{Rate: 1} }},
+		{"script", func(e *engine.Engine) {
+			e.Script = []*engine.ScriptedEvent{{Action: engine.NUKE_CITIES, Cities: []string{"A"}}}
+		}},
+		{"head-on", func(e *engine.Engine) { e.HeadOnCollisions = true }},
+		{"ignore", func(e *engine.Engine) { e.Ignore = map[engine.ExecutionStatus]bool{engine.ALL_ALIENS_STUCK: true} }},
+		{"workers", func(e *engine.Engine) { e.Workers = 4 }},
+		{"world", func(e *engine.Engine) { e.World.Aliens[0].Faction = "red" }},
+	}
+
+	for _, test := range tests {
+		a, b := city.NewCity("A"), city.NewCity("B")
+		e := &engine.Engine{
+			World:   world.NewWorld(world.CityMap{"A": a, "B": b}, world.LinkMap{"A": {world.North: b}}, world.AliensMap{0: alien.NewAlien(0, a)}),
+			MaxRuns: 10,
+			Combat:  engine.MutualDestruction{},
+			Spawn:   &engine.SpawnSchedule{},
+		}
+		if _, err := FromEngine(e); err != nil {
+			t.Fatalf("%s: expected no error before the setup, got %v", test.name, err)
+		}
+		test.setup(e)
+		if _, err := FromEngine(e); err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
+
+func TestTickAllocations(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	w := torus(100, 1000, rng)
+	w.Link(0, world.North, 1, 2) // A weighted road, so that the weighted selection is covered as well
+
+	// Moving the aliens must never allocate, whatever the number of rounds
+	if allocs := testing.AllocsPerRun(50, func() { w.Tick(rng) }); allocs != 0 {
+		t.Errorf("Expected no allocations per round, got %.1f", allocs)
+	}
+}
+
+// Function that builds a square torus of side x side cities, each one linked to its four neighbours,
+// with aliens deployed in random cities
+func torus(side, nAliens int, rng *rand.Rand) *World {
+	names := make([]string, side*side)
+	for i := range names {
+		names[i] = fmt.Sprintf("C%d", i)
+	}
+	w := New(names)
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			from := int32(y*side + x)
+			w.Link(from, world.North, int32(((y+side-1)%side)*side+x), world.DefaultRoadWeight)
+			w.Link(from, world.East, int32(y*side+(x+1)%side), world.DefaultRoadWeight)
+			w.Link(from, world.South, int32(((y+1)%side)*side+x), world.DefaultRoadWeight)
+			w.Link(from, world.West, int32(y*side+(x+side-1)%side), world.DefaultRoadWeight)
+		}
+	}
+	for i := 0; i < nAliens; i++ {
+		w.Deploy(int32(rng.Intn(len(names))))
+	}
+	return w
+}
+
+// Function that returns a copy of the mutable state of a world, used to restart the benchmarks runs
+func snapshot(w *World) *World {
+	c := *w
+	c.destroyed = append(Bitset(nil), w.destroyed...)
+	c.positions = append([]int32(nil), w.positions...)
+	c.dead = append(Bitset(nil), w.dead...)
+	c.stuck = append(Bitset(nil), w.stuck...)
+	c.visitedAt = append([]int32(nil), w.visitedAt...)
+	c.occupant = append([]int32(nil), w.occupant...)
+	return &c
+}
+
+// Benchmark of a single round on a 1M cities map invaded by 100k aliens.
+// The map gets restored once half of the aliens are dead, so that every round moves a comparable number of aliens.
+func BenchmarkTick(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	initial := torus(1000, 100000, rng)
+	w, moves := snapshot(initial), 0
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if w.alive < 50000 {
+			b.StopTimer()
+			w = snapshot(initial)
+			b.StartTimer()
+		}
+		moves += w.alive
+		w.Tick(rng)
+	}
+	b.ReportMetric(float64(moves)/float64(b.N), "moves/op")
+}
+
+// Benchmark of a whole invasion of a 1M cities map by 100k aliens, lasting at most 1000 rounds
+func BenchmarkRun(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	initial := torus(1000, 100000, rng)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		w := snapshot(initial)
+		b.StartTimer()
+		w.Run(1000, rng)
+	}
+}
+
+// Benchmarks of the same invasion of a 10k cities map by 1k aliens, using the engine and the compact world
+func BenchmarkEngineRun(b *testing.B) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(out)
+
+	cities, links, aliens := make(world.CityMap), make(world.LinkMap), make(world.AliensMap)
+	c := torus(100, 1000, rand.New(rand.NewSource(1)))
+	for _, name := range c.names {
+		cities[name] = city.NewCity(name)
+	}
+	for i, name := range c.names {
+		links[name] = make(map[world.Direction]*city.City)
+		for _, d := range world.Directions {
+			links[name][d] = cities[c.names[c.links[i*len(world.Directions)+d]]]
+		}
+	}
+	for a, position := range c.positions {
+		aliens[a] = alien.NewAlien(a, cities[c.names[position]])
+	}
+	initial := world.NewWorld(cities, links, aliens)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		e := &engine.Engine{World: initial.Clone(), MaxRuns: 100, Ignore: map[engine.ExecutionStatus]bool{engine.NO_POSSIBLE_FIGHTS: true}}
+		b.StartTimer()
+		if _, err := e.Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompactRun(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	initial := torus(100, 1000, rng)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		w := snapshot(initial)
+		b.StartTimer()
+		w.Run(100, rng)
+	}
+}
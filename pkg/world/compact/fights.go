@@ -0,0 +1,236 @@
+package compact
+
+import (
+	"github.com/AzraelSec/mad-aliens/pkg/world"
+)
+
+// State of the possible fights check.
+// Moving within a strongly connected component, an alien can still reach the same cities: the outcome only changes
+// when a city is destroyed, or an alien dies or moves to another component. Since the components take a whole visit
+// of the map, they are only computed once the searches have claimed as many cities as the map has since the last time.
+// Otherwise, the two aliens found meeting during the last search are likely to still be close to each other, so they
+// are checked first.
+type fightsCheck struct {
+	claimer   []int32  // Alien that claimed each city during the last search
+	claimedAt []uint32 // Search each city has been claimed during
+	searches  uint32   // Number of searches performed
+	frontier  []int32  // Cities left to visit during a search, each followed by the alien visiting it
+	explored  int      // Number of cities claimed by the searches since the components were computed
+	pair      []int32  // Two aliens that could meet during the last search of all the aliens (nil if none)
+	budget    int      // Number of cities claimed during the last search of all the aliens
+
+	components   []int32 // Strongly connected component of each surviving city (nil if never computed)
+	componentsAt int     // Number of destroyed cities when the components were computed (-1 if outdated)
+	regions      []int32 // Component each alien was in during the last check (or its destroyed city, negated)
+	regionsAt    int     // Number of destroyed cities when the regions were recorded
+	alive        int     // Number of alive aliens when the regions were recorded
+	possible     bool    // Whether any two aliens could meet during the last check
+
+	index   []int32 // Visit order of each city while computing the components
+	low     []int32 // Lowest visit order reachable from each city while computing the components
+	onStack Bitset  // Cities on the stack while computing the components
+	stack   []int32 // Cities whose component is not known yet while computing the components
+	calls   []call  // Cities being visited while computing the components
+}
+
+// City being visited while computing the components, with the direction of the next road to follow
+type call struct {
+	city int32
+	d    int
+}
+
+// Method that checks whether at least two alive aliens may still meet in the same city, like the engine does
+// before each round: it is enough that the sets of cities reachable by two aliens overlap.
+func (w *World) FightsPossible() bool {
+	f := &w.fights
+	valid := f.components != nil && f.componentsAt == w.nDestroyed
+	if valid && f.regionsAt == w.nDestroyed && f.alive == w.alive && w.sameRegions() {
+		return f.possible
+	}
+
+	f.possible = f.pair != nil && !w.dead.Has(f.pair[0]) && !w.dead.Has(f.pair[1]) && w.searchFights(f.pair, f.budget)
+	if !f.possible {
+		f.possible = w.searchFights(nil, len(w.names))
+	}
+	if !valid && f.explored >= len(w.names) {
+		w.computeComponents()
+		valid = true
+	}
+	if valid {
+		if len(f.regions) < len(w.positions) {
+			f.regions = make([]int32, len(w.positions))
+		}
+		for a := range w.positions {
+			f.regions[a] = w.region(w.positions[a])
+		}
+		f.regionsAt, f.alive = w.nDestroyed, w.alive
+	}
+	return f.possible
+}
+
+// Method that returns the component of a surviving city, or the negated destroyed city itself
+func (w *World) region(city int32) int32 {
+	if w.destroyed.Has(city) {
+		return -city - 1
+	}
+	return w.fights.components[city]
+}
+
+// Method that checks whether every alive alien is in the same region as during the last check
+func (w *World) sameRegions() bool {
+	if len(w.fights.regions) < len(w.positions) {
+		return false
+	}
+	for a := int32(0); int(a) < len(w.positions); a++ {
+		if !w.dead.Has(a) && w.fights.regions[a] != w.region(w.positions[a]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Method that looks for two aliens that can reach the same city, among the given ones (or all, if nil), claiming at
+// most a number of cities.
+// A single breadth-first visit starts from all the aliens at the same time, where each city is claimed by the first
+// alien reaching it: as soon as another alien reaches a claimed city, a meeting is possible.
+func (w *World) searchFights(aliens []int32, budget int) bool {
+	f := &w.fights
+	if f.claimer == nil {
+		f.claimer = make([]int32, len(w.names))
+		f.claimedAt = make([]uint32, len(w.names))
+	}
+	f.searches++
+	f.frontier = f.frontier[:0]
+	defer func() {
+		f.explored += len(f.frontier) / 2
+		if aliens == nil {
+			f.budget = len(f.frontier) / 2
+		}
+	}()
+
+	// Function that makes an alien claim a city, returning whether another alien already claimed it
+	visit := func(city, a int32) bool {
+		if f.claimedAt[city] == f.searches {
+			if f.claimer[city] == a {
+				return false
+			}
+			if aliens == nil {
+				f.pair = append(f.pair[:0], f.claimer[city], a)
+			}
+			return true
+		}
+		f.claimedAt[city], f.claimer[city] = f.searches, a
+		f.frontier = append(f.frontier, city, a)
+		return false
+	}
+
+	// Function that makes an alien claim the cities reachable from a city through a single road
+	visitRoads := func(city, a int32) bool {
+		base := int(city) * len(world.Directions)
+		for d := range world.Directions {
+			if to := w.links[base+d]; to != NO_CITY && !w.destroyed.Has(to) && visit(to, a) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Aliens are usually close to each other, so the roads leaving their cities are followed right away.
+	// No fights happen among ruins, but aliens may still leave them through the surviving roads.
+	found := false
+	if aliens == nil {
+		for a := int32(0); !found && int(a) < len(w.positions); a++ {
+			if !w.dead.Has(a) {
+				found = (!w.destroyed.Has(w.positions[a]) && visit(w.positions[a], a)) || visitRoads(w.positions[a], a)
+			}
+		}
+	}
+	for i := 0; !found && i < len(aliens); i++ {
+		a := aliens[i]
+		found = (!w.destroyed.Has(w.positions[a]) && visit(w.positions[a], a)) || visitRoads(w.positions[a], a)
+	}
+	for i := 0; !found && i < len(f.frontier) && len(f.frontier) <= 2*budget; i += 2 {
+		found = visitRoads(f.frontier[i], f.frontier[i+1])
+	}
+	return found
+}
+
+// Method that computes the strongly connected components of the surviving cities, through an iterative version of
+// Tarjan's algorithm
+func (w *World) computeComponents() {
+	const unvisited = -1
+	f := &w.fights
+	n := len(w.names)
+	if f.components == nil {
+		f.components, f.index, f.low, f.onStack = make([]int32, n), make([]int32, n), make([]int32, n), NewBitset(n)
+	}
+	var (
+		components, index, low = f.components, f.index, f.low
+		onStack, stack, calls  = f.onStack, f.stack[:0], f.calls[:0]
+		next, component        int32
+	)
+	for i := range index {
+		index[i] = unvisited
+	}
+
+	for root := int32(0); int(root) < n; root++ {
+		if index[root] != unvisited || w.destroyed.Has(root) {
+			continue
+		}
+		calls = append(calls, call{root, 0})
+		index[root], low[root] = next, next
+		next++
+		stack = append(stack, root)
+		onStack.Set(root)
+
+		for len(calls) > 0 {
+			top := &calls[len(calls)-1]
+			city := top.city
+			if top.d < len(world.Directions) {
+				to := w.links[int(city)*len(world.Directions)+top.d]
+				top.d++
+				switch {
+				case to == NO_CITY || w.destroyed.Has(to):
+				case index[to] == unvisited:
+					index[to], low[to] = next, next
+					next++
+					stack = append(stack, to)
+					onStack.Set(to)
+					calls = append(calls, call{to, 0})
+				case onStack.Has(to):
+					low[city] = minInt32(low[city], index[to])
+				}
+				continue
+			}
+
+			// All the roads have been followed: the city either closes a component or passes its lowlink back
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				parent := calls[len(calls)-1].city
+				low[parent] = minInt32(low[parent], low[city])
+			}
+			if low[city] == index[city] {
+				for {
+					member := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack.Clear(member)
+					components[member] = component
+					if member == city {
+						break
+					}
+				}
+				component++
+			}
+		}
+	}
+
+	f.stack, f.calls = stack, calls
+	f.componentsAt, f.explored = w.nDestroyed, 0
+}
+
+func minInt32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
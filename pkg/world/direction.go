@@ -11,7 +11,11 @@ const (
 
 type Direction = int
 
-func parseDirection(s string) (_ Direction, ok bool) {
+// All the available directions, in their natural order
+var Directions = []Direction{North, East, South, West}
+
+// Function that parses a direction, as used in the world definition (e.g. "north")
+func ParseDirection(s string) (_ Direction, ok bool) {
 	switch s {
 	case "north":
 		return North, true
@@ -26,7 +30,8 @@ func parseDirection(s string) (_ Direction, ok bool) {
 	}
 }
 
-func directionString(d Direction) (string, error) {
+// Function that returns the string representation of a direction, as used in the world definition
+func DirectionString(d Direction) (string, error) {
 	switch d {
 	case North:
 		return "north", nil
@@ -39,3 +44,8 @@ func directionString(d Direction) (string, error) {
 	}
 	return "", errors.New("invalid direction")
 }
+
+// Function that returns the opposite of a direction (e.g. south for north)
+func Opposite(d Direction) Direction {
+	return (d + 2) % len(Directions)
+}
@@ -0,0 +1,131 @@
+package world
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Colors used to render destroyed cities and the roads leading into or out of them
+const (
+	destroyedFillColor = "#d3d3d3"
+	destroyedLineColor = "#a9a9a9"
+)
+
+// Function that quotes a DOT identifier.
+// Only double quotes need to be escaped, so that DOT escape sequences (e.g. \n) are preserved.
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// Method that counts the alive aliens located in each city
+func (w *World) aliensPerCity() map[string]int {
+	counts := make(map[string]int)
+	for _, al := range w.Aliens {
+		if !al.Destroyed && al.City != nil {
+			counts[al.City.Name]++
+		}
+	}
+	return counts
+}
+
+// Method that returns the city names sorted alphabetically.
+// Since maps have no defined order, it is used to produce stable exports.
+func (w *World) SortedCityNames() []string {
+	names := make([]string, 0, len(w.Cities))
+	for name := range w.Cities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Function that returns the directions of a city's links sorted by their value
+func sortedDirections(links map[Direction]*cityLink) []Direction {
+	directions := make([]Direction, 0, len(links))
+	for direction := range links {
+		directions = append(directions, direction)
+	}
+	sort.Ints(directions)
+	return directions
+}
+
+// Internal representation of a road used by the exporters
+type cityLink struct {
+	from, to  string
+	destroyed bool // A boolean indicating if at least one of the road ends has been destroyed
+}
+
+// Method that collects the roads of a city, indexed by direction
+func (w *World) cityLinks(name string) map[Direction]*cityLink {
+	links := make(map[Direction]*cityLink)
+	source := w.Cities[name]
+	for direction, arrival := range w.Links[name] {
+		links[direction] = &cityLink{
+			from:      name,
+			to:        arrival.Name,
+			destroyed: arrival.Destroyed || (source != nil && source.Destroyed),
+		}
+	}
+	return links
+}
+
+// Method that serializes the world as a Graphviz DOT directed graph.
+// Roads are labeled with their direction, destroyed cities (and their roads) are greyed out
+// and each city reports the number of alive aliens located in it.
+func (w *World) WriteDOT(out io.Writer) error {
+	var (
+		b      strings.Builder
+		counts = w.aliensPerCity()
+		names  = w.SortedCityNames()
+	)
+
+	b.WriteString("digraph world {\n")
+	b.WriteString("\tnode [shape=ellipse, style=filled, fillcolor=white];\n")
+
+	for _, name := range names {
+		label := name
+		if n := counts[name]; n > 0 {
+			label = fmt.Sprintf(`%s\n%d aliens`, name, n)
+		}
+
+		attrs := []string{"label=" + dotQuote(label)}
+		if w.Cities[name].Destroyed {
+			attrs = append(
+				attrs,
+				fmt.Sprintf("fillcolor=%q", destroyedFillColor),
+				fmt.Sprintf("fontcolor=%q", destroyedLineColor),
+				fmt.Sprintf("color=%q", destroyedLineColor),
+			)
+		}
+		fmt.Fprintf(&b, "\t%s [%s];\n", dotQuote(name), strings.Join(attrs, ", "))
+	}
+
+	for _, name := range names {
+		links := w.cityLinks(name)
+		for _, direction := range sortedDirections(links) {
+			link := links[direction]
+			directionStr, err := DirectionString(direction)
+			if err != nil {
+				return err
+			}
+
+			attrs := []string{fmt.Sprintf("label=%q", directionStr)}
+			if link.destroyed {
+				attrs = append(
+					attrs,
+					fmt.Sprintf("color=%q", destroyedLineColor),
+					fmt.Sprintf("fontcolor=%q", destroyedLineColor),
+					"style=dashed",
+				)
+			}
+			fmt.Fprintf(&b, "\t%s -> %s [%s];\n", dotQuote(link.from), dotQuote(link.to), strings.Join(attrs, ", "))
+		}
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
@@ -0,0 +1,75 @@
+package world
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+)
+
+func TestWriteDOT(t *testing.T) {
+	var (
+		a = city.NewCity("A")
+		b = city.NewCity("B")
+		d = city.NewCity("D")
+	)
+	d.Destroyed = true
+
+	w := NewWorld(
+		CityMap{"A": a, "B": b, "D": d},
+		LinkMap{
+			"A": map[Direction]*city.City{North: b, West: d},
+			"B": map[Direction]*city.City{South: a},
+		},
+		AliensMap{0: alien.NewAlien(0, a), 1: alien.NewAlien(1, a)},
+	)
+
+	var out strings.Builder
+	if err := w.WriteDOT(&out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `digraph world {
+	node [shape=ellipse, style=filled, fillcolor=white];
+	"A" [label="A\n2 aliens"];
+	"B" [label="B"];
+	"D" [label="D", fillcolor="#d3d3d3", fontcolor="#a9a9a9", color="#a9a9a9"];
+	"A" -> "B" [label="north"];
+	"A" -> "D" [label="west", color="#a9a9a9", fontcolor="#a9a9a9", style=dashed];
+	"B" -> "A" [label="south"];
+}
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	var (
+		a = city.NewCity("A")
+		b = city.NewCity("B&C")
+	)
+
+	w := NewWorld(
+		CityMap{"A": a, "B&C": b},
+		LinkMap{"A": map[Direction]*city.City{East: b}},
+		AliensMap{0: alien.NewAlien(0, b)},
+	)
+
+	var out strings.Builder
+	if err := w.WriteSVG(&out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	svg := out.String()
+	for _, wanted := range []string{"<svg", "</svg>", ">A</text>", ">B&amp;C</text>", ">east</text>", ">1 aliens</text>"} {
+		if !strings.Contains(svg, wanted) {
+			t.Errorf("Expected SVG to contain %s", wanted)
+		}
+	}
+
+	if n := strings.Count(svg, "<circle"); n != 2 {
+		t.Errorf("Expected 2 cities, got %d", n)
+	}
+}
@@ -0,0 +1,101 @@
+package world
+
+import "fmt"
+
+// Error returned when a requested city does not exist
+type CityNotFoundError struct {
+	Name string
+}
+
+func (e *CityNotFoundError) Error() string {
+	return fmt.Sprintf("cannot find requested city: %s", e.Name)
+}
+
+// Error returned when a requested alien does not exist
+type AlienNotFoundError struct {
+	Id int
+}
+
+func (e *AlienNotFoundError) Error() string {
+	return fmt.Sprintf("cannot find requested alien: %d", e.Id)
+}
+
+// Error returned when a city with the same name already exists
+type CityExistsError struct {
+	Name string
+}
+
+func (e *CityExistsError) Error() string {
+	return fmt.Sprintf("city already exists: %s", e.Name)
+}
+
+// Error returned when a name cannot be used for a city, since the world definition could not represent it
+type InvalidNameError struct {
+	Name string
+}
+
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("invalid city name: %q", e.Name)
+}
+
+// Error returned when a city to add is missing
+type NilCityError struct{}
+
+func (e *NilCityError) Error() string {
+	return "cannot add a nil city"
+}
+
+// Error returned when an operation requires a surviving city
+type CityDestroyedError struct {
+	Name string
+}
+
+func (e *CityDestroyedError) Error() string {
+	return fmt.Sprintf("city has been destroyed: %s", e.Name)
+}
+
+// Error returned when an operation requires an alive alien
+type AlienDestroyedError struct {
+	Id int
+}
+
+func (e *AlienDestroyedError) Error() string {
+	return fmt.Sprintf("alien has been destroyed: %d", e.Id)
+}
+
+// Error returned when a road leaving a city in a direction already exists
+type RoadExistsError struct {
+	From      string
+	Direction Direction
+}
+
+func (e *RoadExistsError) Error() string {
+	return fmt.Sprintf("a road already leaves %s toward %s", e.From, directionName(e.Direction))
+}
+
+// Error returned when no road leaves a city in a direction
+type RoadNotFoundError struct {
+	From      string
+	Direction Direction
+}
+
+func (e *RoadNotFoundError) Error() string {
+	return fmt.Sprintf("no road leaves %s toward %s", e.From, directionName(e.Direction))
+}
+
+// Error returned when the attributes of a road are not valid
+type InvalidRoadError struct {
+	Road Road
+}
+
+func (e *InvalidRoadError) Error() string {
+	return fmt.Sprintf("invalid road attributes: %+v", e.Road)
+}
+
+// Function that returns the name of a direction, or its number if it is not valid
+func directionName(d Direction) string {
+	if name, err := DirectionString(d); err == nil {
+		return name
+	}
+	return fmt.Sprintf("direction %d", d)
+}
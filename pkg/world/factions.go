@@ -0,0 +1,89 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+)
+
+// Share of the aliens that belong to a faction
+type FactionShare struct {
+	Faction string  // Faction name
+	Ratio   float64 // Relative share of the aliens (shares do not need to sum up to 1)
+}
+
+// Function that parses a comma separated list of faction shares (e.g. "red=0.5,blue=0.5")
+func ParseFactionShares(s string) ([]FactionShare, error) {
+	shares := make([]FactionShare, 0)
+	if strings.TrimSpace(s) == "" {
+		return shares, nil
+	}
+
+	for _, item := range strings.Split(s, ",") {
+		sep := strings.Index(item, "=")
+		if sep <= 0 {
+			return nil, fmt.Errorf("invalid faction share: %s", item)
+		}
+
+		ratio, err := strconv.ParseFloat(item[sep+1:], 64)
+		if err != nil || ratio < 0 {
+			return nil, fmt.Errorf("invalid faction ratio: %s", item[sep+1:])
+		}
+		shares = append(shares, FactionShare{Faction: strings.TrimSpace(item[:sep]), Ratio: ratio})
+	}
+
+	return shares, nil
+}
+
+// Method that randomly splits the aliens into factions, according to the given shares.
+// The number of aliens of each faction is proportional to its share (rounding with the largest remainder method),
+// while the aliens joining each faction are picked randomly.
+func (w *World) AssignFactions(shares []FactionShare) {
+	total := 0.0
+	for _, share := range shares {
+		total += share.Ratio
+	}
+	if total == 0 {
+		return
+	}
+
+	var (
+		ids        = w.SortedAlienIds()
+		counts     = make([]int, len(shares))
+		remainders = make([]int, len(shares))
+		assigned   = 0
+	)
+
+	for i, share := range shares {
+		exact := share.Ratio / total * float64(len(ids))
+		counts[i] = int(math.Floor(exact))
+		assigned += counts[i]
+		remainders[i] = i
+	}
+
+	sort.SliceStable(remainders, func(i, j int) bool {
+		ri := shares[remainders[i]].Ratio/total*float64(len(ids)) - float64(counts[remainders[i]])
+		rj := shares[remainders[j]].Ratio/total*float64(len(ids)) - float64(counts[remainders[j]])
+		return ri > rj
+	})
+	for i := 0; assigned < len(ids); i, assigned = i+1, assigned+1 {
+		counts[remainders[i%len(remainders)]]++
+	}
+
+	// Fisher-Yates shuffle, so that factions are not assigned by identification number
+	for i := len(ids) - 1; i > 0; i-- {
+		j := utils.RandomInt(i + 1)
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+
+	next := 0
+	for i, share := range shares {
+		for n := 0; n < counts[i]; n, next = n+1, next+1 {
+			w.Aliens[ids[next]].Faction = share.Faction
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package world
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A position on the world grid.
+// X grows eastward and Y grows southward, so that north of (0, 0) is (0, -1).
+type Position struct {
+	X, Y int
+}
+
+// Contradiction between a road direction and the positions inferred from the other roads.
+// For example, if A is north of B and B is north of A, one of the two roads contradicts the other.
+type Contradiction struct {
+	From      string    // Source city of the contradicting road
+	To        string    // Target city of the contradicting road
+	Direction Direction // Direction of the contradicting road
+	Expected  Position  // Position the target city should have according to the road
+	Actual    Position  // Position already assigned to the target city
+}
+
+func (c Contradiction) String() string {
+	directionStr, _ := DirectionString(c.Direction)
+	return fmt.Sprintf(
+		"%s %s=%s expects %s at (%d, %d), but it is at (%d, %d)",
+		c.From, directionStr, c.To, c.To,
+		c.Expected.X, c.Expected.Y,
+		c.Actual.X, c.Actual.Y,
+	)
+}
+
+// Layout assigning grid coordinates to the world cities
+type Layout struct {
+	Positions      map[string]Position // A map that associates each city name to its position
+	Contradictions []Contradiction     // Roads whose direction is not consistent with the positions
+}
+
+// Function that returns the grid offset a road in the given direction implies
+func directionOffset(d Direction) Position {
+	switch d {
+	case North:
+		return Position{0, -1}
+	case East:
+		return Position{1, 0}
+	case South:
+		return Position{0, 1}
+	case West:
+		return Position{-1, 0}
+	}
+	return Position{}
+}
+
+// Method that infers the cities' coordinates from the direction of their roads.
+// Roads are considered regardless of their orientation (if A north=B, then A is south of B)
+// and destroyed cities are included, since destruction does not change the geography.
+// Each connected group of cities is placed on the right of the previous one, and the first
+// position assigned to a city wins: roads that disagree with it are reported as contradictions.
+func (w *World) Layout() *Layout {
+	type edge struct {
+		from, to  string
+		direction Direction
+	}
+
+	var (
+		names    = w.SortedCityNames()
+		adjacent = make(map[string][]edge)
+		layout   = &Layout{Positions: make(map[string]Position, len(names))}
+		nextX    = 0
+	)
+
+	// Each road is indexed on both its ends, so that it can be followed backwards
+	for _, name := range names {
+		links := w.Links[name]
+		directions := make([]Direction, 0, len(links))
+		for direction := range links {
+			directions = append(directions, direction)
+		}
+		sort.Ints(directions)
+
+		for _, direction := range directions {
+			e := edge{name, links[direction].Name, direction}
+			adjacent[e.from] = append(adjacent[e.from], e)
+			if e.to != e.from {
+				adjacent[e.to] = append(adjacent[e.to], e)
+			}
+		}
+	}
+
+	for _, root := range names {
+		if _, placed := layout.Positions[root]; placed {
+			continue
+		}
+
+		// Breadth-first visit of the group of cities connected to root
+		component := []string{root}
+		checked := make(map[edge]bool)
+		layout.Positions[root] = Position{}
+
+		for i := 0; i < len(component); i++ {
+			current := component[i]
+			for _, e := range adjacent[current] {
+				if checked[e] {
+					continue
+				}
+				checked[e] = true
+
+				offset := directionOffset(e.direction)
+				from, to := layout.Positions[e.from], layout.Positions[e.to]
+				expected := Position{from.X + offset.X, from.Y + offset.Y}
+
+				if e.from == current {
+					if _, placed := layout.Positions[e.to]; !placed {
+						layout.Positions[e.to] = expected
+						component = append(component, e.to)
+						continue
+					}
+				} else {
+					if _, placed := layout.Positions[e.from]; !placed {
+						layout.Positions[e.from] = Position{to.X - offset.X, to.Y - offset.Y}
+						component = append(component, e.from)
+						continue
+					}
+				}
+
+				if expected != to {
+					layout.Contradictions = append(layout.Contradictions, Contradiction{
+						From:      e.from,
+						To:        e.to,
+						Direction: e.direction,
+						Expected:  expected,
+						Actual:    to,
+					})
+				}
+			}
+		}
+
+		// The component is translated so that it starts on the right of the previous ones
+		minX, minY, maxX := 0, 0, 0
+		for _, name := range component {
+			p := layout.Positions[name]
+			minX, minY, maxX = minInt(minX, p.X), minInt(minY, p.Y), maxInt(maxX, p.X)
+		}
+		for _, name := range component {
+			p := layout.Positions[name]
+			layout.Positions[name] = Position{p.X - minX + nextX, p.Y - minY}
+		}
+		nextX += maxX - minX + 2
+	}
+
+	return layout
+}
+
+// Method that returns the Manhattan distance between two cities on the grid
+func (l *Layout) Distance(a, b string) (int, error) {
+	pa, exists := l.Positions[a]
+	if !exists {
+		return 0, &CityNotFoundError{Name: a}
+	}
+	pb, exists := l.Positions[b]
+	if !exists {
+		return 0, &CityNotFoundError{Name: b}
+	}
+	return abs(pa.X-pb.X) + abs(pa.Y-pb.Y), nil
+}
+
+// Method that returns the size of the grid needed to contain all the cities
+func (l *Layout) Size() (width int, height int) {
+	for _, p := range l.Positions {
+		width, height = maxInt(width, p.X+1), maxInt(height, p.Y+1)
+	}
+	return width, height
+}
+
+// Method that checks whether at least two cities share the same position
+func (l *Layout) HasOverlaps() bool {
+	taken := make(map[Position]bool, len(l.Positions))
+	for _, p := range l.Positions {
+		if taken[p] {
+			return true
+		}
+		taken[p] = true
+	}
+	return false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,113 @@
+package world
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	var tests = []struct {
+		input             string
+		wantedPositions   map[string]Position
+		wantedConflicts   int
+		wantedOverlapping bool
+	}{
+		// Consistent roads in every direction
+		{
+			input: `B north=A east=C south=D west=E
+							A south=B`,
+			wantedPositions: map[string]Position{
+				"A": {1, 0},
+				"B": {1, 1},
+				"C": {2, 1},
+				"D": {1, 2},
+				"E": {0, 1},
+			},
+			wantedConflicts: 0,
+		},
+		// A north of B and B north of A
+		{
+			input: `A north=B
+							B north=A`,
+			wantedPositions: map[string]Position{
+				"A": {0, 1},
+				"B": {0, 0},
+			},
+			wantedConflicts: 1,
+		},
+		// Disconnected groups are placed side by side
+		{
+			input: `A east=B
+							C south=D`,
+			wantedPositions: map[string]Position{
+				"A": {0, 0},
+				"B": {1, 0},
+				"C": {3, 0},
+				"D": {3, 1},
+			},
+			wantedConflicts: 0,
+		},
+		// Two paths leading to different cells for the same city
+		{
+			input: `A east=B south=C
+							B south=C`,
+			wantedPositions: map[string]Position{
+				"A": {0, 0},
+				"B": {1, 0},
+				"C": {0, 1},
+			},
+			wantedConflicts: 1,
+		},
+		// Two roads in opposite directions towards the same city
+		{
+			input:           `A east=B west=B`,
+			wantedConflicts: 1,
+		},
+		// Two cities placed in the same cell
+		{
+			input: `A north=B
+							C north=B`,
+			wantedConflicts:   0,
+			wantedOverlapping: true,
+		},
+	}
+
+	for _, test := range tests {
+		w, err := Parse(strings.NewReader(test.input), 0)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		layout := w.Layout()
+		for name, wanted := range test.wantedPositions {
+			if got := layout.Positions[name]; got != wanted {
+				t.Errorf("Expected %s at %v, got %v", name, wanted, got)
+			}
+		}
+
+		if len(layout.Contradictions) != test.wantedConflicts {
+			t.Errorf("Expected %d contradictions, got %v", test.wantedConflicts, layout.Contradictions)
+		}
+
+		if layout.HasOverlaps() != test.wantedOverlapping {
+			t.Errorf("Expected overlapping to be %t", test.wantedOverlapping)
+		}
+	}
+}
+
+func TestLayoutDistance(t *testing.T) {
+	w, err := Parse(strings.NewReader(`A east=B
+																		B south=C`), 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	layout := w.Layout()
+	if d, err := layout.Distance("A", "C"); err != nil || d != 2 {
+		t.Errorf("Expected distance 2, got %d (%v)", d, err)
+	}
+
+	if _, err := layout.Distance("A", "Z"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
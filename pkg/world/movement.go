@@ -0,0 +1,355 @@
+package world
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+)
+
+// Constants that define the names of the available movement strategies
+const (
+	UNIFORM_MOVEMENT  = "uniform"
+	LAZY_MOVEMENT     = "lazy"
+	EXPLORER_MOVEMENT = "explorer"
+	HUNTER_MOVEMENT   = "hunter"
+	COWARD_MOVEMENT   = "coward"
+	BIASED_MOVEMENT   = "biased"
+	FORWARD_MOVEMENT  = "forward"
+)
+
+// Available movement strategies
+var MovementStrategies = []string{UNIFORM_MOVEMENT, LAZY_MOVEMENT, EXPLORER_MOVEMENT, HUNTER_MOVEMENT, COWARD_MOVEMENT, BIASED_MOVEMENT, FORWARD_MOVEMENT}
+
+// Road an alien can follow to leave its city
+type Move struct {
+	Direction Direction  // Direction of the road
+	City      *city.City // City the road leads to
+	Road      Road       // Attributes of the road
+}
+
+// Strategy that chooses the next move of an alien among the available ones, which are never empty.
+// Returning nil makes the alien stay in its city for the round.
+// Strategies draw their random values from the given source, and must not change the world, since the moves of
+// different aliens may be chosen concurrently.
+type MovementStrategy interface {
+	Choose(w *World, a *alien.Alien, moves []Move, r utils.Random) *Move
+}
+
+// Strategy that picks a random move, proportionally to the roads weight (uniformly if they all have the default one)
+type UniformMovement struct{}
+
+func (UniformMovement) Choose(_ *World, _ *alien.Alien, moves []Move, r utils.Random) *Move {
+	return weightedMove(moves, func(Move) float64 { return 1 }, r)
+}
+
+// Strategy that stays put with the given probability, and otherwise moves as the next strategy (uniform if nil)
+type LazyMovement struct {
+	Probability float64
+	Then        MovementStrategy
+}
+
+func (s LazyMovement) Choose(w *World, a *alien.Alien, moves []Move, r utils.Random) *Move {
+	if r.Float64() < s.Probability {
+		return nil
+	}
+	if s.Then == nil {
+		return UniformMovement{}.Choose(w, a, moves, r)
+	}
+	return s.Then.Choose(w, a, moves, r)
+}
+
+// Strategy that prefers the cities the alien has never visited, moving randomly when all of them were visited
+type ExplorerMovement struct{}
+
+func (ExplorerMovement) Choose(w *World, a *alien.Alien, moves []Move, r utils.Random) *Move {
+	return randomMoveAmong(moves, func(m Move) bool { return a.Visited[m.City.Name] == 0 }, r)
+}
+
+// Strategy that avoids going back to the city the alien just came from, unless it is the only way out
+type ForwardMovement struct{}
+
+func (ForwardMovement) Choose(_ *World, a *alien.Alien, moves []Move, r utils.Random) *Move {
+	previous, exists := a.PreviousCity()
+	return randomMoveAmong(moves, func(m Move) bool { return !exists || m.City.Name != previous }, r)
+}
+
+// Strategy that moves toward the nearest alien of another faction (any other alien for aliens without a faction),
+// moving randomly when none of them can be reached. Defenders hunt any alien, and aliens in transit are hunted in the
+// city they are traveling to.
+// During a round, the hunters of the same class share the distances computed for the first of them, so they chase
+// the aliens where they were at that time.
+type HunterMovement struct{}
+
+func (HunterMovement) Choose(w *World, a *alien.Alien, moves []Move, r utils.Random) *Move {
+	class := huntingClass{any: a.IsDefender() || a.Faction == ""}
+	if !class.any {
+		class.faction = a.Faction
+	}
+	field := w.huntingField(class)
+
+	// The hunter is not a target of its own, so its city is ignored unless other targets are in it
+	excluded := ""
+	if class.any && !a.IsDefender() && field.targets[a.City.Name] == 1 {
+		excluded = a.City.Name
+	}
+
+	best := -1
+	for _, m := range moves {
+		if d, reachable := field.distance(m.City.Name, excluded); reachable && (best == -1 || d < best) {
+			best = d
+		}
+	}
+	if best == -1 {
+		return UniformMovement{}.Choose(w, a, moves, r)
+	}
+	return randomMoveAmong(moves, func(m Move) bool {
+		d, reachable := field.distance(m.City.Name, excluded)
+		return reachable && d == best
+	}, r)
+}
+
+// Strategy that avoids the cities occupied by other aliens, moving randomly when all of them are occupied
+type CowardMovement struct{}
+
+func (CowardMovement) Choose(w *World, a *alien.Alien, moves []Move, r utils.Random) *Move {
+	occupied := make(map[string]bool)
+	for _, other := range w.Aliens {
+		if other.Id != a.Id && !other.Destroyed {
+			occupied[other.City.Name] = true
+		}
+	}
+	return randomMoveAmong(moves, func(m Move) bool { return !occupied[m.City.Name] }, r)
+}
+
+// Strategy that picks a random move, where each direction is weighted (directions without a weight count as 1)
+// on top of the roads weight
+type BiasedMovement struct {
+	Weights map[Direction]float64
+}
+
+func (s BiasedMovement) Choose(_ *World, _ *alien.Alien, moves []Move, r utils.Random) *Move {
+	return weightedMove(moves, func(m Move) float64 {
+		if weight, exists := s.Weights[m.Direction]; exists {
+			return weight
+		}
+		return 1
+	}, r)
+}
+
+// Function that picks a random move among the preferred ones, or among all of them if none is preferred
+func randomMoveAmong(moves []Move, preferred func(Move) bool, r utils.Random) *Move {
+	candidates := make([]Move, 0, len(moves))
+	for _, m := range moves {
+		if preferred(m) {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = moves
+	}
+	return weightedMove(candidates, func(Move) float64 { return 1 }, r)
+}
+
+// Function that picks a random move with a probability proportional to the road weight times the given bias.
+// If the bias excludes every move, they all are equally likely.
+func weightedMove(moves []Move, bias func(Move) float64, r utils.Random) *Move {
+	weights, total := make([]float64, len(moves)), 0.0
+	for i, m := range moves {
+		weights[i] = m.Road.Weight * bias(m)
+		total += weights[i]
+	}
+	if total <= 0 {
+		return &moves[r.Intn(len(moves))]
+	}
+
+	roll := r.Float64() * total
+	for i := range moves {
+		if roll < weights[i] {
+			return &moves[i]
+		}
+		roll -= weights[i]
+	}
+	return &moves[len(moves)-1]
+}
+
+// Class of hunters sharing the same targets: the aliens of a faction hunt the aliens of the other ones, while
+// defenders and the aliens without a faction hunt any alien
+type huntingClass struct {
+	any     bool
+	faction string
+}
+
+// Target city at some distance
+type huntingTarget struct {
+	city     string
+	distance int
+}
+
+// Distances separating the cities from the targets of a class of hunters
+type huntingField struct {
+	nearest map[string][]huntingTarget // Two nearest target cities of each surviving city at most, by distance
+	targets map[string]int             // Number of targets in each city
+}
+
+// Distance fields of the hunters computed during a round, shared by the hunters of the same class
+type huntingFields struct {
+	sync.Mutex
+	fields map[huntingClass]*huntingField
+}
+
+// Method that returns the number of roads separating a city from the nearest target city but the excluded one.
+// Unreachable cities are reported as such.
+func (f *huntingField) distance(name, excluded string) (int, bool) {
+	for _, t := range f.nearest[name] {
+		if t.city != excluded {
+			return t.distance, true
+		}
+	}
+	return 0, false
+}
+
+// Method that returns the distance field of a class of hunters, computed once per round.
+// Since moves may be chosen concurrently, the first hunter of each class computes it while the others wait.
+func (w *World) huntingField(class huntingClass) *huntingField {
+	h := w.hunting
+	if h == nil {
+		return w.newHuntingField(class)
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	field, exists := h.fields[class]
+	if !exists {
+		if h.fields == nil {
+			h.fields = make(map[huntingClass]*huntingField)
+		}
+		field = w.newHuntingField(class)
+		h.fields[class] = field
+	}
+	return field
+}
+
+// Method that computes the two nearest target cities of each surviving city, for a class of hunters.
+// Keeping the second one lets each hunter ignore its own city.
+func (w *World) newHuntingField(class huntingClass) *huntingField {
+	type visit struct {
+		city   string
+		target huntingTarget
+	}
+	field := &huntingField{nearest: make(map[string][]huntingTarget), targets: make(map[string]int)}
+	visits := make([]visit, 0)
+	for _, other := range w.Aliens {
+		target := other.NextCity()
+		if other.Destroyed || target.Destroyed || other.IsDefender() || (!class.any && other.Faction == class.faction) {
+			continue
+		}
+		if field.targets[target.Name]++; field.targets[target.Name] == 1 {
+			t := huntingTarget{city: target.Name}
+			field.nearest[target.Name] = []huntingTarget{t}
+			visits = append(visits, visit{target.Name, t})
+		}
+	}
+
+	// Roads are followed backward, starting from all the targets at the same time
+	incoming := make(map[string][]string)
+	for from, links := range w.Links {
+		if w.Cities[from].Destroyed {
+			continue
+		}
+		for _, to := range links {
+			if !to.Destroyed {
+				incoming[to.Name] = append(incoming[to.Name], from)
+			}
+		}
+	}
+
+	// Each city is reached at most twice, by its nearest target cities first
+	for ; len(visits) > 0; visits = visits[1:] {
+		v := visits[0]
+		for _, from := range incoming[v.city] {
+			nearest := field.nearest[from]
+			if len(nearest) == 2 || (len(nearest) == 1 && nearest[0].city == v.target.city) {
+				continue
+			}
+			t := huntingTarget{city: v.target.city, distance: v.target.distance + 1}
+			field.nearest[from] = append(nearest, t)
+			visits = append(visits, visit{from, t})
+		}
+	}
+	return field
+}
+
+// Function that parses a movement strategy, in the format name[:parameters].
+// The lazy strategy requires the probability of staying put (e.g. "lazy:0.3"), while the biased one
+// requires a slash separated list of direction weights (e.g. "biased:north=3/east=2").
+func ParseMovementStrategy(s string) (MovementStrategy, error) {
+	name, params := strings.TrimSpace(s), ""
+	if sep := strings.Index(name, ":"); sep != -1 {
+		name, params = name[:sep], name[sep+1:]
+	}
+
+	switch name {
+	case UNIFORM_MOVEMENT:
+		return UniformMovement{}, nil
+	case EXPLORER_MOVEMENT:
+		return ExplorerMovement{}, nil
+	case HUNTER_MOVEMENT:
+		return HunterMovement{}, nil
+	case COWARD_MOVEMENT:
+		return CowardMovement{}, nil
+	case FORWARD_MOVEMENT:
+		return ForwardMovement{}, nil
+	case LAZY_MOVEMENT:
+		p, err := strconv.ParseFloat(params, 64)
+		if err != nil || p < 0 || p > 1 {
+			return nil, fmt.Errorf("invalid lazy probability: %s", params)
+		}
+		return LazyMovement{Probability: p}, nil
+	case BIASED_MOVEMENT:
+		weights := make(map[Direction]float64)
+		for _, item := range strings.Split(params, "/") {
+			kv := strings.Split(item, "=")
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid direction weight: %s", item)
+			}
+			d, ok := ParseDirection(kv[0])
+			if !ok {
+				return nil, fmt.Errorf("invalid direction: %s", kv[0])
+			}
+			weight, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil || weight < 0 {
+				return nil, fmt.Errorf("invalid direction weight: %s", item)
+			}
+			weights[d] = weight
+		}
+		return BiasedMovement{Weights: weights}, nil
+	default:
+		return nil, fmt.Errorf("unknown movement strategy: %s", name)
+	}
+}
+
+// Function that parses a comma separated list of movement strategies per faction (e.g. "red=hunter,blue=lazy:0.5")
+func ParseFactionStrategies(s string) (map[string]MovementStrategy, error) {
+	strategies := make(map[string]MovementStrategy)
+	if strings.TrimSpace(s) == "" {
+		return strategies, nil
+	}
+
+	for _, item := range strings.Split(s, ",") {
+		sep := strings.Index(item, "=")
+		if sep == -1 || strings.TrimSpace(item[:sep]) == "" {
+			return nil, fmt.Errorf("invalid faction strategy: %s", item)
+		}
+		strategy, err := ParseMovementStrategy(item[sep+1:])
+		if err != nil {
+			return nil, err
+		}
+		strategies[strings.TrimSpace(item[:sep])] = strategy
+	}
+	return strategies, nil
+}
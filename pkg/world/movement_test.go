@@ -0,0 +1,265 @@
+package world
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+)
+
+func TestMovementStrategies(t *testing.T) {
+	// Star map: A leads north to B, east to C and south to D, while D leads back to A
+	var (
+		a = city.NewCity("A")
+		b = city.NewCity("B")
+		c = city.NewCity("C")
+		d = city.NewCity("D")
+		e = city.NewCity("E")
+	)
+	newWorld := func() (*World, *alien.Alien) {
+		mover := alien.NewAlien(0, a)
+		w := NewWorld(
+			CityMap{"A": a, "B": b, "C": c, "D": d, "E": e},
+			LinkMap{
+				"A": map[Direction]*city.City{North: b, East: c, South: d},
+				"C": map[Direction]*city.City{East: e},
+				"D": map[Direction]*city.City{North: a},
+			},
+			AliensMap{mover.Id: mover},
+		)
+		return w, mover
+	}
+
+	var tests = []struct {
+		name     string
+		strategy MovementStrategy
+		setup    func(w *World)
+		wanted   []string // Cities the alien may end up in
+	}{
+		{"lazy", LazyMovement{Probability: 1}, nil, []string{"A"}},
+		{"biased", BiasedMovement{Weights: map[Direction]float64{North: 0, East: 0}}, nil, []string{"D"}},
+		{
+			"explorer",
+			ExplorerMovement{},
+			func(w *World) {
+				w.Aliens[0].RecordStep(alien.Step{City: "B"}, 0)
+				w.Aliens[0].RecordStep(alien.Step{City: "D"}, 0)
+			},
+			[]string{"C"},
+		},
+		{
+			"hunter",
+			HunterMovement{},
+			func(w *World) { w.Aliens[1] = alien.NewAlien(1, e) },
+			[]string{"C"},
+		},
+		{
+			"hunter of travelers",
+			HunterMovement{},
+			func(w *World) {
+				traveler := alien.NewAlien(1, b)
+				traveler.Transit = &alien.Transit{From: b, To: e, Remaining: 2}
+				w.Aliens[1] = traveler
+			},
+			[]string{"C"},
+		},
+		{
+			"hunter of its own city",
+			HunterMovement{},
+			func(w *World) { w.Aliens[1] = alien.NewAlien(1, a) },
+			[]string{"D"},
+		},
+		{
+			"forward",
+			ForwardMovement{},
+			func(w *World) {
+				w.Aliens[0].RecordStep(alien.Step{City: "C", Direction: "east"}, 0)
+				w.Aliens[0].RecordStep(alien.Step{City: "A", Direction: "west"}, 0)
+			},
+			[]string{"B", "D"},
+		},
+		{
+			"coward",
+			CowardMovement{},
+			func(w *World) {
+				w.Aliens[1] = alien.NewAlien(1, b)
+				w.Aliens[2] = alien.NewAlien(2, c)
+			},
+			[]string{"D"},
+		},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 20; i++ {
+			w, mover := newWorld()
+			if test.setup != nil {
+				test.setup(w)
+			}
+			w.SetStrategy(mover.Id, test.strategy)
+
+			if _, err := w.Move(mover.Id); err != nil {
+				t.Fatal(err)
+			}
+			if !contains(test.wanted, mover.City.Name) {
+				t.Errorf("%s: expected the alien in %v, got %s", test.name, test.wanted, mover.City.Name)
+				break
+			}
+			if mover.Stuck {
+				t.Errorf("%s: expected the alien not to be stuck", test.name)
+			}
+		}
+	}
+}
+
+func TestHuntingField(t *testing.T) {
+	w, err := Parse(strings.NewReader("A east=B\nB east=C west=A\nC west=B"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"A", "A", "C"} {
+		w.Aliens[i] = alien.NewAlien(i, w.Cities[name])
+	}
+	w.Aliens[1].Faction, w.Aliens[2].Faction = "red", "red"
+
+	// Without rounds, each hunter computes its own distances
+	if w.huntingField(huntingClass{any: true}) == w.huntingField(huntingClass{any: true}) {
+		t.Errorf("Expected the distances not to be shared outside rounds")
+	}
+
+	w.StartRound(0)
+	all, red := w.huntingField(huntingClass{any: true}), w.huntingField(huntingClass{faction: "red"})
+	if all != w.huntingField(huntingClass{any: true}) || red != w.huntingField(huntingClass{faction: "red"}) {
+		t.Errorf("Expected the distances to be shared during the round")
+	}
+	if d, _ := all.distance("B", ""); d != 1 {
+		t.Errorf("Expected B to be 1 road away from the aliens, got %d", d)
+	}
+	if d, _ := all.distance("A", "A"); d != 2 {
+		t.Errorf("Expected A to be 2 roads away from C, got %d", d)
+	}
+	if d, _ := red.distance("C", ""); d != 2 {
+		t.Errorf("Expected C to be 2 roads away from the red alien targets, got %d", d)
+	}
+
+	w.StartRound(1)
+	if all == w.huntingField(huntingClass{any: true}) {
+		t.Errorf("Expected the distances to be computed again in the next round")
+	}
+}
+
+func TestStrategyOf(t *testing.T) {
+	var (
+		loner  = alien.NewAlien(0, nil)
+		red    = alien.NewAlien(1, nil)
+		custom = alien.NewAlien(2, nil)
+	)
+	red.Faction, custom.Faction = "red", "red"
+
+	w := NewWorld(CityMap{}, LinkMap{}, AliensMap{0: loner, 1: red, 2: custom})
+	w.DefaultStrategy = CowardMovement{}
+	w.FactionStrategies = map[string]MovementStrategy{"red": HunterMovement{}}
+	w.SetStrategy(custom.Id, ExplorerMovement{})
+
+	if _, ok := w.StrategyOf(loner).(CowardMovement); !ok {
+		t.Errorf("Expected the default strategy, got %T", w.StrategyOf(loner))
+	}
+	if _, ok := w.StrategyOf(red).(HunterMovement); !ok {
+		t.Errorf("Expected the faction strategy, got %T", w.StrategyOf(red))
+	}
+	if _, ok := w.StrategyOf(custom).(ExplorerMovement); !ok {
+		t.Errorf("Expected the alien strategy, got %T", w.StrategyOf(custom))
+	}
+}
+
+func TestMoveAlong(t *testing.T) {
+	var tests = []struct {
+		name    string
+		destroy []string // Cities destroyed between the choice and the move
+		wanted  string   // City the alien ends up in
+		moved   bool
+		stuck   bool
+	}{
+		{"unchanged", nil, "B", true, false},
+		{"destination destroyed", []string{"B"}, "C", true, false},
+		{"all destroyed", []string{"B", "C"}, "A", false, true},
+	}
+
+	for _, test := range tests {
+		w, err := Parse(strings.NewReader("A north=B east=C\nB south=A\nC west=A"), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mover, err := w.SpawnAlien("A")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.SetStrategy(mover.Id, BiasedMovement{Weights: map[Direction]float64{East: 0}})
+
+		// Choosing a move does not change the world
+		steps := len(mover.Path)
+		move, err := w.ChooseMove(mover.Id, utils.Shared)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if move == nil || move.City.Name != "B" || mover.City.Name != "A" || len(mover.Path) != steps {
+			t.Fatalf("%s: expected the alien to choose B while staying in A, got %+v", test.name, move)
+		}
+
+		if err := w.DestroyCities(test.destroy); err != nil {
+			t.Fatal(err)
+		}
+		moved, err := w.MoveAlong(mover.Id, move, utils.Shared)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if moved != test.moved || mover.City.Name != test.wanted || mover.Stuck != test.stuck {
+			t.Errorf("%s: expected the alien in %s (moved %t, stuck %t), got %s (moved %t, stuck %t)",
+				test.name, test.wanted, test.moved, test.stuck, mover.City.Name, moved, mover.Stuck)
+		}
+	}
+}
+
+func TestParseMovementStrategy(t *testing.T) {
+	var tests = []struct {
+		input       string
+		expectError bool
+	}{
+		{"uniform", false},
+		{"lazy:0.3", false},
+		{"explorer", false},
+		{"hunter", false},
+		{"coward", false},
+		{"forward", false},
+		{"biased:north=3/east=0.5", false},
+		{"lazy", true},
+		{"lazy:2", true},
+		{"biased:up=3", true},
+		{"biased:north", true},
+		{"teleport", true},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseMovementStrategy(test.input); (err != nil) != test.expectError {
+			t.Errorf("%s: expected error %t, got %v", test.input, test.expectError, err)
+		}
+	}
+
+	strategies, err := ParseFactionStrategies("red=hunter,blue=lazy:0.5")
+	if err != nil || len(strategies) != 2 {
+		t.Errorf("Unexpected faction strategies %v (%v)", strategies, err)
+	}
+	if _, err := ParseFactionStrategies("red"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package world
+
+import (
+	"strings"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+)
+
+// Method that adds a new city to the world, without any road.
+// Its name must be unique and representable in the world definition (no spaces, '=' or ':').
+func (w *World) AddCity(c *city.City) error {
+	if c == nil {
+		return &NilCityError{}
+	}
+	if err := validCityName(c.Name); err != nil {
+		return err
+	}
+	if _, exists := w.Cities[c.Name]; exists {
+		return &CityExistsError{Name: c.Name}
+	}
+
+	w.Cities[c.Name] = c
+	if owned, generation := w.owns(w.owned, c.Name); !owned {
+		if w.owned == nil {
+			w.owned = make(map[string]uint32)
+		}
+		w.owned[c.Name] = generation
+	}
+	if c.Destroyed {
+		w.markDestroyed(c)
+	}
+	w.topology++
+	return nil
+}
+
+// Method that opens a new road leaving a city in a direction, toward another existing city.
+// A road leaving the city in the same direction must not exist already.
+func (w *World) AddRoad(from string, d Direction, to string, road Road) error {
+	if _, err := w.findCityPointer(from); err != nil {
+		return err
+	}
+	target, err := w.findCityPointer(to)
+	if err != nil {
+		return err
+	}
+	if _, exists := w.Links[from][d]; exists {
+		return &RoadExistsError{From: from, Direction: d}
+	}
+	if _, err := DirectionString(d); err != nil || road.Weight <= 0 || road.Time < 1 || road.Capacity < 0 {
+		return &InvalidRoadError{Road: road}
+	}
+
+	w.writableLinks(from)[d] = target
+	if road != DefaultRoad() {
+		w.writableRoads(from)[d] = road
+	}
+	w.topology++
+	return nil
+}
+
+// Method that closes the road leaving a city in a direction, along with its attributes.
+// Aliens already traveling along the road still reach their destination.
+func (w *World) RemoveRoad(from string, d Direction) error {
+	if _, err := w.findCityPointer(from); err != nil {
+		return err
+	}
+	if _, exists := w.Links[from][d]; !exists {
+		return &RoadNotFoundError{From: from, Direction: d}
+	}
+
+	delete(w.writableLinks(from), d)
+	if len(w.Links[from]) == 0 {
+		delete(w.Links, from)
+	}
+	if _, exists := w.Roads[from][d]; exists {
+		delete(w.writableRoads(from), d)
+		if len(w.Roads[from]) == 0 {
+			delete(w.Roads, from)
+		}
+	}
+	delete(w.traversals, roadKey{from, d})
+	w.topology++
+	return nil
+}
+
+// Method that instantly moves an alien into a surviving city, interrupting its travel, if any
+func (w *World) TeleportAlien(id int, name string) error {
+	al, err := w.findAlienPointer(id)
+	if err != nil {
+		return err
+	}
+	if al.Destroyed {
+		return &AlienDestroyedError{Id: id}
+	}
+	c, err := w.findCityPointer(name)
+	if err != nil {
+		return err
+	}
+	if c.Destroyed {
+		return &CityDestroyedError{Name: name}
+	}
+
+	al.City, al.Transit, al.Stuck = c, nil, false
+	al.RecordStep(alien.Step{Round: w.Round, City: name}, w.pathLimit())
+	return nil
+}
+
+// Method that renames a city, updating every index that refers to it by name, including the aliens paths
+func (w *World) RenameCity(name string, newName string) error {
+	if _, err := w.findCityPointer(name); err != nil {
+		return err
+	}
+	if name == newName {
+		return nil
+	}
+	if err := validCityName(newName); err != nil {
+		return err
+	}
+	if _, exists := w.Cities[newName]; exists {
+		return &CityExistsError{Name: newName}
+	}
+	c, err := w.writableCity(name)
+	if err != nil {
+		return err
+	}
+	for _, owned := range []map[string]uint32{w.owned, w.ownedLinks, w.ownedRoads} {
+		if generation, exists := owned[name]; exists {
+			owned[newName] = generation
+			delete(owned, name)
+		}
+	}
+
+	c.Name = newName
+	w.topology++
+	w.Cities[newName] = c
+	delete(w.Cities, name)
+	if links, exists := w.Links[name]; exists {
+		w.Links[newName] = links
+		delete(w.Links, name)
+	}
+	if roads, exists := w.Roads[name]; exists {
+		w.Roads[newName] = roads
+		delete(w.Roads, name)
+	}
+	for _, d := range Directions {
+		if n, exists := w.traversals[roadKey{name, d}]; exists {
+			w.traversals[roadKey{newName, d}] = n
+			delete(w.traversals, roadKey{name, d})
+		}
+	}
+	if round, exists := w.destroyedAt[name]; exists {
+		w.destroyedAt[newName] = round
+		delete(w.destroyedAt, name)
+	}
+	if original, exists := w.originals[name]; exists {
+		original.Name = newName
+		w.originals[newName] = original
+		delete(w.originals, name)
+	}
+
+	for _, al := range w.Aliens {
+		for i := range al.Path {
+			if al.Path[i].City == name {
+				al.Path[i].City = newName
+			}
+		}
+		if visits, exists := al.Visited[name]; exists {
+			al.Visited[newName] = visits
+			delete(al.Visited, name)
+		}
+	}
+	return nil
+}
+
+// Function that checks whether a city name can be represented in the world definition
+func validCityName(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t\n=:") {
+		return &InvalidNameError{Name: name}
+	}
+	return nil
+}
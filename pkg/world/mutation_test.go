@@ -0,0 +1,170 @@
+package world
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+)
+
+// Function that checks whether an error has the same type of the wanted one (nil if no error is wanted)
+func sameErrorType(err error, wanted error) bool {
+	if wanted == nil || err == nil {
+		return err == wanted
+	}
+	return reflect.TypeOf(err) == reflect.TypeOf(wanted)
+}
+
+func TestRoads(t *testing.T) {
+	var (
+		a = city.NewCity("A")
+		b = city.NewCity("B")
+	)
+	w := NewWorld(CityMap{"A": a, "B": b}, LinkMap{"A": {North: b}}, AliensMap{})
+
+	var tests = []struct {
+		name      string
+		mutate    func() error
+		wantedErr error
+	}{
+		{"existing road", func() error { return w.AddRoad("A", North, "B", DefaultRoad()) }, &RoadExistsError{}},
+		{"unknown source", func() error { return w.AddRoad("Z", North, "B", DefaultRoad()) }, &CityNotFoundError{}},
+		{"unknown target", func() error { return w.AddRoad("B", South, "Z", DefaultRoad()) }, &CityNotFoundError{}},
+		{"invalid road", func() error { return w.AddRoad("B", South, "A", Road{Weight: 0, Time: 1}) }, &InvalidRoadError{}},
+		{"invalid direction", func() error { return w.AddRoad("B", 7, "A", DefaultRoad()) }, &InvalidRoadError{}},
+		{"new road", func() error { return w.AddRoad("B", South, "A", Road{Weight: 2, Time: 3}) }, nil},
+		{"closed road", func() error { return w.RemoveRoad("A", North) }, nil},
+		{"missing road", func() error { return w.RemoveRoad("A", North) }, &RoadNotFoundError{}},
+		{"missing city", func() error { return w.RemoveRoad("Z", North) }, &CityNotFoundError{}},
+	}
+
+	for _, test := range tests {
+		if err := test.mutate(); !sameErrorType(err, test.wantedErr) {
+			t.Errorf("%s: expected error %T, got %v", test.name, test.wantedErr, err)
+		}
+	}
+
+	if _, exists := w.Links["A"]; exists {
+		t.Errorf("Expected the links of A to be removed, got %v", w.Links["A"])
+	}
+	if w.Links["B"][South] != a || w.Road("B", South) != (Road{Weight: 2, Time: 3}) {
+		t.Errorf("Expected a road from B to A, got %v (%+v)", w.Links["B"], w.Road("B", South))
+	}
+}
+
+func TestAddCity(t *testing.T) {
+	w := NewWorld(CityMap{"A": city.NewCity("A")}, LinkMap{}, AliensMap{})
+
+	var tests = []struct {
+		name      string
+		wantedErr error
+	}{
+		{"A", &CityExistsError{}},
+		{"", &InvalidNameError{}},
+		{"New York", &InvalidNameError{}},
+		{"B:pop=3", &InvalidNameError{}},
+		{"B", nil},
+	}
+
+	for _, test := range tests {
+		if err := w.AddCity(city.NewCity(test.name)); !sameErrorType(err, test.wantedErr) {
+			t.Errorf("%q: expected error %T, got %v", test.name, test.wantedErr, err)
+		}
+	}
+
+	if err := w.AddCity(nil); !sameErrorType(err, &NilCityError{}) {
+		t.Errorf("nil: expected error %T, got %v", &NilCityError{}, err)
+	}
+
+	// New cities can be linked and deployed aliens into right away
+	if err := w.AddRoad("A", East, "B", DefaultRoad()); err != nil {
+		t.Fatal(err)
+	}
+	if al, err := w.SpawnAlien("B"); err != nil || al.City != w.Cities["B"] {
+		t.Errorf("Unexpected spawned alien %+v (%v)", al, err)
+	}
+	if _, err := w.SpawnAlien("C"); !errors.As(err, new(*CityNotFoundError)) {
+		t.Errorf("Expected a CityNotFoundError, got %v", err)
+	}
+}
+
+func TestTeleportAlien(t *testing.T) {
+	var (
+		a = city.NewCity("A")
+		b = city.NewCity("B")
+		c = city.NewCity("C")
+	)
+	c.Destroyed = true
+	traveler, dead := alien.NewAlien(0, a), alien.NewAlien(1, a)
+	traveler.Transit = &alien.Transit{From: a, To: b, Remaining: 2}
+	dead.Destroyed = true
+	w := NewWorld(CityMap{"A": a, "B": b, "C": c}, LinkMap{}, AliensMap{0: traveler, 1: dead})
+
+	var tests = []struct {
+		id        int
+		city      string
+		wantedErr error
+	}{
+		{0, "C", &CityDestroyedError{}},
+		{0, "Z", &CityNotFoundError{}},
+		{1, "B", &AlienDestroyedError{}},
+		{2, "B", &AlienNotFoundError{}},
+		{0, "B", nil},
+	}
+
+	for _, test := range tests {
+		if err := w.TeleportAlien(test.id, test.city); !sameErrorType(err, test.wantedErr) {
+			t.Errorf("%d to %s: expected error %T, got %v", test.id, test.city, test.wantedErr, err)
+		}
+	}
+
+	if traveler.City != b || traveler.InTransit() || traveler.Path[len(traveler.Path)-1].City != "B" {
+		t.Errorf("Expected alien 0 in B, got %+v", traveler)
+	}
+}
+
+func TestRenameCity(t *testing.T) {
+	var (
+		a = city.NewCity("A")
+		b = city.NewCity("B")
+	)
+	mover := alien.NewAlien(0, a)
+	w := NewWorld(CityMap{"A": a, "B": b}, LinkMap{"A": {North: b}, "B": {South: a}}, AliensMap{0: mover})
+	w.Roads = RoadMap{"A": {North: Road{Weight: 2, Time: 1}}}
+	if err := w.DestroyCities([]string{"B"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		from, to  string
+		wantedErr error
+	}{
+		{"Z", "Y", &CityNotFoundError{}},
+		{"A", "B", &CityExistsError{}},
+		{"A", "", &InvalidNameError{}},
+		{"A", "A", nil},
+		{"A", "Alpha", nil},
+		{"B", "Beta", nil},
+	}
+
+	for _, test := range tests {
+		if err := w.RenameCity(test.from, test.to); !sameErrorType(err, test.wantedErr) {
+			t.Errorf("%s to %s: expected error %T, got %v", test.from, test.to, test.wantedErr, err)
+		}
+	}
+
+	if _, exists := w.Cities["A"]; exists || w.Cities["Alpha"] != a || a.Name != "Alpha" {
+		t.Errorf("Expected A to be renamed to Alpha, got %v", w.Cities)
+	}
+	if w.Links["Alpha"][North] != b || w.Links["Beta"][South] != a || w.Road("Alpha", North).Weight != 2 {
+		t.Errorf("Expected the roads to follow the renamed cities, got %v", w.Links)
+	}
+	if pending := w.RebuildPending(); len(pending) != 1 || pending[0] != "Beta" {
+		t.Errorf("Expected Beta waiting to be rebuilt, got %v", pending)
+	}
+	if mover.Path[0].City != "Alpha" || mover.Visited["Alpha"] != 1 || mover.Visited["A"] != 0 {
+		t.Errorf("Expected the alien path to follow the renamed city, got %+v", mover.Path)
+	}
+}
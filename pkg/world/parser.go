@@ -19,6 +19,7 @@ func Parse(in io.Reader, nAliens int) (*World, error) {
 		scanner = bufio.NewScanner(in)
 		cities  = make(CityMap)
 		links   = make(LinkMap)
+		roads   = make(RoadMap)
 
 		// This slice keeps track of the created city names.
 		// In this way, no additional loops are lately performed to deploy the aliens.
@@ -30,9 +31,20 @@ func Parse(in io.Reader, nAliens int) (*World, error) {
 		items := strings.Split(strings.TrimSpace(line), " ")
 		sourceName, directions := items[0], items[1:]
 
+		// City attributes, if any, follow the city name
+		attributes := ""
+		if sep := strings.Index(sourceName, ":"); sep != -1 {
+			sourceName, attributes = sourceName[:sep], sourceName[sep+1:]
+		}
+
 		// Create a city and add it to the cities map
 		attachCity(city.NewCity(sourceName), cities)
 		ids = append(ids, sourceName)
+		if attributes != "" {
+			if err := parseCityAttributes(cities[sourceName], attributes); err != nil {
+				return nil, err
+			}
+		}
 
 		// The links map uses the directions as a key since a single link
 		// can exist per each direction per each city.
@@ -45,12 +57,30 @@ func Parse(in io.Reader, nAliens int) (*World, error) {
 			}
 
 			directionName := directionConfig[:sep]
-			direction, ok := parseDirection(directionName)
+			direction, ok := ParseDirection(directionName)
 			if !ok {
 				return nil, errors.New("invalid direction: " + directionName)
 			}
 
+			// Road attributes, if any, follow the target city name
 			targetName := directionConfig[sep+1:]
+			if attrSep := strings.Index(targetName, ":"); attrSep != -1 {
+				road, err := ParseRoad(targetName[attrSep+1:])
+				if err != nil {
+					return nil, err
+				}
+				targetName = targetName[:attrSep]
+				if roads[sourceName] == nil {
+					roads[sourceName] = map[Direction]Road{}
+				}
+				roads[sourceName][direction] = road
+			} else if _, exists := roads[sourceName][direction]; exists {
+				// A road defined again without attributes has the default ones
+				delete(roads[sourceName], direction)
+				if len(roads[sourceName]) == 0 {
+					delete(roads, sourceName)
+				}
+			}
 			ids = append(ids, targetName)
 
 			var target *city.City
@@ -67,7 +97,7 @@ func Parse(in io.Reader, nAliens int) (*World, error) {
 		}
 	}
 
-	return NewWorld(
+	w := NewWorld(
 		cities,
 		links,
 		deployAliens(
@@ -75,7 +105,9 @@ func Parse(in io.Reader, nAliens int) (*World, error) {
 			cities,
 			ids,
 		),
-	), nil
+	)
+	w.Roads = roads
+	return w, nil
 }
 
 // Method that adds a city to the cities map
@@ -99,7 +131,7 @@ func deployAliens(nAliens int, cp CityMap, cityNames []string) AliensMap {
 	for i := 0; i < nAliens; i++ {
 		idx := utils.RandomInt(len(cityNames))
 		aliens[i] = alien.NewAlien(i, cp[cityNames[idx]])
-		log.Printf("Alien %d is located at %s", aliens[i].Id, aliens[i].City.Name)
+		log.Printf("%s is located at %s", aliens[i], aliens[i].City.Name)
 	}
 	return aliens
 }
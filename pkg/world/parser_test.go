@@ -107,7 +107,7 @@ func TestParse(t *testing.T) {
 		for _, city := range test.wantedCities {
 			if links, exists := test.wantedLinks[city]; exists {
 				for direction, arrival := range links {
-					strDirection, _ := directionString(direction)
+					strDirection, _ := DirectionString(direction)
 
 					if _, exists := w.Links[city][direction]; !exists {
 						t.Errorf(
@@ -0,0 +1,68 @@
+package world
+
+import (
+	"sort"
+
+	"github.com/AzraelSec/mad-aliens/pkg/city"
+)
+
+// Method that records the destruction of a city, so that it can be rebuilt later
+func (w *World) markDestroyed(c *city.City) {
+	if w.destroyedAt == nil {
+		w.destroyedAt = make(map[string]int)
+	}
+	w.destroyedAt[c.Name] = w.Round
+	w.topology++
+}
+
+// Method that saves the original attributes of a city before it takes any damage
+func (w *World) saveOriginal(c *city.City) {
+	if w.originals == nil {
+		w.originals = make(map[string]city.City)
+	}
+	if _, exists := w.originals[c.Name]; !exists {
+		w.originals[c.Name] = *c
+	}
+}
+
+// Method that returns the names of the destroyed cities that may be rebuilt, in alphabetical order
+func (w *World) RebuildPending() []string {
+	names := make([]string, 0, len(w.destroyedAt))
+	for name := range w.destroyedAt {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Method that rebuilds the cities destroyed at least the given number of rounds ago, as long as no aliens are in them.
+// A rebuilt city gets back its original attributes, and its roads get reconnected since links to destroyed cities
+// are never removed from the links map. The names of the rebuilt cities are returned in alphabetical order.
+func (w *World) RebuildCities(after int) []string {
+	occupied := make(map[string]bool)
+	for _, al := range w.Aliens {
+		if !al.Destroyed && !al.InTransit() {
+			occupied[al.City.Name] = true
+		}
+	}
+
+	rebuilt := make([]string, 0)
+	for _, name := range w.RebuildPending() {
+		if w.Round-w.destroyedAt[name] < after || occupied[name] {
+			continue
+		}
+
+		c, err := w.writableCity(name)
+		if err != nil {
+			continue
+		}
+		if original, exists := w.originals[name]; exists {
+			c.Population, c.Defense, c.HitPoints = original.Population, original.Defense, original.HitPoints
+		}
+		c.Destroyed = false
+		delete(w.destroyedAt, name)
+		w.topology++
+		rebuilt = append(rebuilt, name)
+	}
+	return rebuilt
+}
@@ -0,0 +1,53 @@
+package world
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+)
+
+func TestRebuildCities(t *testing.T) {
+	w, err := Parse(strings.NewReader("A:pop=10,hp=5 north=B\nB south=A"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.StartRound(0)
+	if destroyed, err := w.DamageCity("A", 50); err != nil || !destroyed {
+		t.Fatalf("Expected A to be destroyed, got %t (%v)", destroyed, err)
+	}
+	if err := w.DestroyCities([]string{"B"}); err != nil {
+		t.Fatal(err)
+	}
+	survivor := alien.NewAlien(0, w.Cities["B"])
+	w.Aliens[0] = survivor
+
+	w.StartRound(1)
+	if rebuilt := w.RebuildCities(2); len(rebuilt) != 0 {
+		t.Errorf("Expected no rebuilt cities, got %v", rebuilt)
+	}
+
+	// Cities with aliens in them are not rebuilt
+	w.StartRound(2)
+	if rebuilt := w.RebuildCities(2); len(rebuilt) != 1 || rebuilt[0] != "A" {
+		t.Errorf("Expected A to be rebuilt, got %v", rebuilt)
+	}
+	if a := w.Cities["A"]; a.Destroyed || a.HitPoints != 5 || a.Population != 10 || a.Casualties != 10 {
+		t.Errorf("Expected A to get its original attributes back, got %+v", a)
+	}
+	if pending := w.RebuildPending(); len(pending) != 1 || pending[0] != "B" {
+		t.Errorf("Expected B to be pending, got %v", pending)
+	}
+
+	survivor.Destroyed = true
+	w.StartRound(3)
+	if rebuilt := w.RebuildCities(2); len(rebuilt) != 1 || rebuilt[0] != "B" {
+		t.Errorf("Expected B to be rebuilt, got %v", rebuilt)
+	}
+
+	// The original roads are reconnected
+	if s := w.String(); s != "A:pop=10,hp=5 north=B\nB south=A" {
+		t.Errorf("Unexpected world %q", s)
+	}
+}
@@ -0,0 +1,104 @@
+package world
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Default road attributes, used for the roads defined without them
+const (
+	DefaultRoadWeight = 1.0
+	DefaultRoadTime   = 1
+)
+
+// Attributes of a road, declared in the world definition after the target city (e.g. north=Bar:w=3,cap=2,t=2)
+type Road struct {
+	Weight   float64 // How likely the road is to be taken, compared to the other roads leaving the same city
+	Time     int     // Number of rounds needed to travel along the road
+	Capacity int     // Max number of aliens traversing the road per round (unlimited if zero)
+}
+
+// Function that returns the attributes of a road defined without them
+func DefaultRoad() Road {
+	return Road{Weight: DefaultRoadWeight, Time: DefaultRoadTime}
+}
+
+// Method that serializes the road attributes that differ from the default ones, with the world definition format
+func (r Road) String() string {
+	attrs := make([]string, 0, 3)
+	if r.Weight != DefaultRoadWeight {
+		attrs = append(attrs, "w="+strconv.FormatFloat(r.Weight, 'g', -1, 64))
+	}
+	if r.Capacity != 0 {
+		attrs = append(attrs, "cap="+strconv.Itoa(r.Capacity))
+	}
+	if r.Time != DefaultRoadTime {
+		attrs = append(attrs, "t="+strconv.Itoa(r.Time))
+	}
+	return strings.Join(attrs, ",")
+}
+
+// Function that parses a comma separated list of road attributes (e.g. w=3,cap=2,t=2)
+func ParseRoad(s string) (Road, error) {
+	road := DefaultRoad()
+	for _, item := range strings.Split(s, ",") {
+		kv := strings.Split(item, "=")
+		if len(kv) != 2 {
+			return road, fmt.Errorf("invalid road attribute: %s", item)
+		}
+
+		switch kv[0] {
+		case "w":
+			weight, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil || weight <= 0 {
+				return road, fmt.Errorf("invalid road weight: %s", kv[1])
+			}
+			road.Weight = weight
+		case "t":
+			time, err := strconv.Atoi(kv[1])
+			if err != nil || time < 1 {
+				return road, fmt.Errorf("invalid road travel time: %s", kv[1])
+			}
+			road.Time = time
+		case "cap":
+			capacity, err := strconv.Atoi(kv[1])
+			if err != nil || capacity < 1 {
+				return road, fmt.Errorf("invalid road capacity: %s", kv[1])
+			}
+			road.Capacity = capacity
+		default:
+			return road, fmt.Errorf("unknown road attribute: %s", kv[0])
+		}
+	}
+	return road, nil
+}
+
+// Method that returns the attributes of the road leaving a city in a direction
+func (w *World) Road(from string, d Direction) Road {
+	if road, exists := w.Roads[from][d]; exists {
+		return road
+	}
+	return DefaultRoad()
+}
+
+// Method that checks whether a road can still be traversed during the current round
+func (w *World) roadAvailable(from string, d Direction) bool {
+	capacity := w.Road(from, d).Capacity
+	return capacity == 0 || w.traversals[roadKey{from, d}] < capacity
+}
+
+// Method that records an alien traversing a road during the current round
+func (w *World) traverse(from string, d Direction) {
+	if w.traversals == nil {
+		w.traversals = make(map[roadKey]int)
+	}
+	w.traversals[roadKey{from, d}]++
+}
+
+// Method that starts a new round, resetting the roads capacity
+func (w *World) StartRound(round int) {
+	w.Round = round
+	w.traversals = nil
+	w.hunting = &huntingFields{}
+}
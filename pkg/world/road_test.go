@@ -0,0 +1,129 @@
+package world
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AzraelSec/mad-aliens/pkg/alien"
+	"github.com/AzraelSec/mad-aliens/pkg/utils"
+)
+
+func TestParseRoads(t *testing.T) {
+	var tests = []struct {
+		input        string
+		parseError   bool
+		wantedRoad   Road
+		wantedLine   string
+		wantedCities []string
+	}{
+		{"A north=B:w=3,cap=2,t=2", false, Road{Weight: 3, Time: 2, Capacity: 2}, "A north=B:w=3,cap=2,t=2", []string{"A", "B"}},
+		{"A north=B:w=0.5", false, Road{Weight: 0.5, Time: 1}, "A north=B:w=0.5", []string{"A", "B"}},
+		{"A north=B", false, DefaultRoad(), "A north=B", []string{"A", "B"}},
+		{"A north=B:w=3,t=2 north=C", false, DefaultRoad(), "A north=C", []string{"A", "B", "C"}},
+		{"A north=B:w=3\nA north=B:t=2", false, Road{Weight: 1, Time: 2}, "A north=B:t=2", []string{"A", "B"}},
+		{"A north=B:w=0", true, Road{}, "", nil},
+		{"A north=B:cap=-1", true, Road{}, "", nil},
+		{"A north=B:t=0", true, Road{}, "", nil},
+		{"A north=B:speed=3", true, Road{}, "", nil},
+		{"A north=B:w", true, Road{}, "", nil},
+	}
+
+	for _, test := range tests {
+		w, err := Parse(strings.NewReader(test.input), 0)
+		if test.parseError {
+			if err == nil {
+				t.Errorf("%s: expected error, got nil", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", test.input, err)
+			continue
+		}
+
+		if road := w.Road("A", North); road != test.wantedRoad {
+			t.Errorf("%s: expected road %+v, got %+v", test.input, test.wantedRoad, road)
+		}
+		for _, name := range test.wantedCities {
+			if _, exists := w.Cities[name]; !exists {
+				t.Errorf("%s: expected city %s to exist", test.input, name)
+			}
+		}
+		// Road attributes survive the serialization
+		if w.String() != test.wantedLine {
+			t.Errorf("Expected %s, got %s", test.wantedLine, w.String())
+		}
+	}
+}
+
+func TestRoadCapacity(t *testing.T) {
+	w, err := Parse(strings.NewReader("A north=B:cap=1"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id := 0; id < 2; id++ {
+		w.Aliens[id] = alien.NewAlien(id, w.Cities["A"])
+	}
+
+	// Only one alien may traverse the road per round, while the other one waits without being stuck
+	w.StartRound(0)
+	for _, id := range []int{0, 1} {
+		if _, err := w.Move(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if w.Aliens[0].City.Name != "B" || w.Aliens[1].City.Name != "A" || w.Aliens[1].Stuck {
+		t.Errorf("Expected alien 0 in B and alien 1 waiting in A, got %s and %s", w.Aliens[0].City.Name, w.Aliens[1].City.Name)
+	}
+
+	// The capacity is restored at every round
+	w.StartRound(1)
+	if moved, err := w.Move(1); err != nil || !moved {
+		t.Errorf("Expected alien 1 to move, got %t (%v)", moved, err)
+	}
+}
+
+func TestRoadWeights(t *testing.T) {
+	w, err := Parse(strings.NewReader("A north=B:w=1000 south=C:w=0.001"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moves := w.AvailableMoves("A")
+	counts := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		counts[UniformMovement{}.Choose(w, nil, moves, utils.Shared).City.Name]++
+	}
+	if counts["B"] < 90 {
+		t.Errorf("Expected the heavy road to be preferred, got %v", counts)
+	}
+}
+
+func TestTravel(t *testing.T) {
+	w, err := Parse(strings.NewReader("A north=B:t=3"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traveler := alien.NewAlien(0, w.Cities["A"])
+	w.Aliens[0] = traveler
+
+	if moved, err := w.Move(0); err != nil || !moved || !traveler.InTransit() || traveler.City.Name != "A" {
+		t.Fatalf("Expected the alien to leave A, got moved %t (%v)", moved, err)
+	}
+	if _, err := w.Move(0); err == nil {
+		t.Errorf("Expected aliens in transit not to move, got nil")
+	}
+
+	for round, wanted := range []bool{false, true} {
+		w.StartRound(round + 1)
+		if arrived, err := w.Travel(0); err != nil || arrived != wanted {
+			t.Errorf("Expected arrived %t at round %d, got %t (%v)", wanted, round+1, arrived, err)
+		}
+	}
+	if traveler.InTransit() || traveler.City.Name != "B" {
+		t.Errorf("Expected the alien in B, got %s", traveler.City.Name)
+	}
+	if last := traveler.Path[len(traveler.Path)-1]; last.Round != 2 || last.City != "B" || last.Direction != "north" {
+		t.Errorf("Unexpected arrival step %+v", last)
+	}
+}
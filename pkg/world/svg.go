@@ -0,0 +1,145 @@
+package world
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+)
+
+// Constants that define the SVG rendering geometry
+const (
+	svgNodeRadius = 28.0
+	svgMargin     = 60.0
+	svgMinRadius  = 120.0
+	svgCellSize   = 140.0
+)
+
+type svgPoint struct {
+	X, Y float64
+}
+
+// Method that assigns a drawing position to each city.
+// Cities are placed on the grid inferred from their roads; if the roads are so inconsistent
+// that two cities would share the same cell, they are placed on a circle instead.
+func (w *World) svgPositions(names []string) (map[string]svgPoint, float64, float64) {
+	positions := make(map[string]svgPoint, len(names))
+
+	if layout := w.Layout(); !layout.HasOverlaps() {
+		cols, rows := layout.Size()
+		for name, p := range layout.Positions {
+			positions[name] = svgPoint{
+				X: svgMargin + svgCellSize*(float64(p.X)+0.5),
+				Y: svgMargin + svgCellSize*(float64(p.Y)+0.5),
+			}
+		}
+		return positions, 2*svgMargin + svgCellSize*float64(cols), 2*svgMargin + svgCellSize*float64(rows)
+	}
+
+	var (
+		radius = math.Max(svgMinRadius, float64(len(names))*svgNodeRadius*2.5/(2*math.Pi))
+		size   = 2 * (radius + svgMargin)
+	)
+
+	for i, name := range names {
+		angle := 2*math.Pi*float64(i)/float64(len(names)) - math.Pi/2
+		positions[name] = svgPoint{
+			X: size/2 + radius*math.Cos(angle),
+			Y: size/2 + radius*math.Sin(angle),
+		}
+	}
+
+	return positions, size, size
+}
+
+// Method that renders the world as a standalone SVG image.
+// It follows the same conventions of WriteDOT: roads are labeled with their direction,
+// destroyed cities are greyed out and alien counts are reported below each city name.
+func (w *World) WriteSVG(out io.Writer) error {
+	var (
+		b                        strings.Builder
+		counts                   = w.aliensPerCity()
+		names                    = w.SortedCityNames()
+		positions, width, height = w.svgPositions(names)
+	)
+
+	fmt.Fprintf(
+		&b,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="sans-serif" font-size="12">`+"\n",
+		width, height, width, height,
+	)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="context-stroke"/></marker></defs>` + "\n")
+
+	for _, name := range names {
+		links := w.cityLinks(name)
+		for _, direction := range sortedDirections(links) {
+			link := links[direction]
+			directionStr, err := DirectionString(direction)
+			if err != nil {
+				return err
+			}
+
+			from, to := positions[link.from], positions[link.to]
+			dx, dy := to.X-from.X, to.Y-from.Y
+			length := math.Hypot(dx, dy)
+			if length == 0 {
+				// Self-loops have no meaningful straight representation
+				continue
+			}
+
+			// Roads start and end on the city circles border
+			ux, uy := dx/length, dy/length
+			x1, y1 := from.X+ux*svgNodeRadius, from.Y+uy*svgNodeRadius
+			x2, y2 := to.X-ux*svgNodeRadius, to.Y-uy*svgNodeRadius
+
+			color, dash := "black", ""
+			if link.destroyed {
+				color, dash = destroyedLineColor, ` stroke-dasharray="4 4"`
+			}
+
+			fmt.Fprintf(
+				&b,
+				`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"%s marker-end="url(#arrow)"/>`+"\n",
+				x1, y1, x2, y2, color, dash,
+			)
+			// Labels are shifted towards the source so that opposite roads do not overlap
+			fmt.Fprintf(
+				&b,
+				`<text x="%.1f" y="%.1f" fill="%s" text-anchor="middle">%s</text>`+"\n",
+				x1+(x2-x1)/3, y1+(y2-y1)/3, color, directionStr,
+			)
+		}
+	}
+
+	for _, name := range names {
+		p := positions[name]
+		fill, stroke := "white", "black"
+		if w.Cities[name].Destroyed {
+			fill, stroke = destroyedFillColor, destroyedLineColor
+		}
+
+		fmt.Fprintf(
+			&b,
+			`<circle cx="%.1f" cy="%.1f" r="%.0f" fill="%s" stroke="%s"/>`+"\n",
+			p.X, p.Y, svgNodeRadius, fill, stroke,
+		)
+		fmt.Fprintf(
+			&b,
+			`<text x="%.1f" y="%.1f" fill="%s" text-anchor="middle">%s</text>`+"\n",
+			p.X, p.Y, stroke, html.EscapeString(name),
+		)
+		if n := counts[name]; n > 0 {
+			fmt.Fprintf(
+				&b,
+				`<text x="%.1f" y="%.1f" fill="%s" text-anchor="middle" font-size="10">%d aliens</text>`+"\n",
+				p.X, p.Y+14, stroke, n,
+			)
+		}
+	}
+
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
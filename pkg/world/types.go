@@ -8,11 +8,49 @@ import (
 type AliensMap = map[int]*alien.Alien
 type CityMap map[string]*city.City
 type LinkMap map[string]map[Direction]*city.City
+type RoadMap map[string]map[Direction]Road
+
+// Road identified by the city it leaves and its direction
+type roadKey struct {
+	from      string
+	direction Direction
+}
 
 type World struct {
-	Cities          CityMap   // A map that associates each city name to its object
-	Links           LinkMap   // A map that assiciates each city name to its available links
-	Aliens          AliensMap // A map that associates each alien name to its alien
-	StuckAliens     int       // A stuck aliens counter
-	DestroyedAliens int       // A destroyed aliens counter
+	Cities CityMap   // A map that associates each city name to its object
+	Links  LinkMap   // A map that assiciates each city name to its available links
+	Aliens AliensMap // A map that associates each alien name to its alien
+	Roads  RoadMap   // A map that associates each link to its road attributes, if they are not the default ones
+
+	Strategies        map[int]MovementStrategy    // Movement strategy of single aliens, overriding the faction and default ones
+	FactionStrategies map[string]MovementStrategy // Movement strategy of the aliens of each faction, overriding the default one
+	DefaultStrategy   MovementStrategy            // Movement strategy of all the other aliens (uniform if nil)
+	DefenderStrategy  MovementStrategy            // Movement strategy of the defenders without their own (the default one if nil)
+
+	Round     int // Round currently being executed, used to record the aliens paths
+	PathLimit int // Max number of steps recorded in each alien path (DefaultPathLimit if zero, unbounded if negative)
+
+	traversals  map[roadKey]int      // Number of aliens that traversed each road during the current round
+	destroyedAt map[string]int       // Round each destroyed city has been destroyed during
+	originals   map[string]city.City // Attributes of the damaged cities before the invasion, restored when rebuilt
+	nextId      int                  // Id of the next spawned alien, unless an alien got added with it meanwhile
+	hunting     *huntingFields       // Distance fields of the hunters during the current round (not shared if nil)
+	topology    uint64               // Number of changes to the surviving cities and their roads
+
+	generation uint32            // Number of forks the cities and the roads got shared by (none if zero), accessed atomically
+	owned      map[string]uint32 // Generation each city not shared with a forked world got copied at
+	ownedLinks map[string]uint32 // Generation the links leaving each city got copied at
+	ownedRoads map[string]uint32 // Generation the roads attributes leaving each city got copied at
+}
+
+// World statistics, derived from the state of its aliens and cities
+type Stats struct {
+	AliveAliens     int `json:"alive_aliens"`     // Number of alive aliens (including the stuck ones, but not the defenders)
+	StuckAliens     int `json:"stuck_aliens"`     // Number of alive aliens that could not move the last time they tried
+	DestroyedAliens int `json:"destroyed_aliens"` // Number of aliens killed during fights
+	SurvivingCities int `json:"surviving_cities"` // Number of cities that have not been destroyed
+	DestroyedCities int `json:"destroyed_cities"` // Number of destroyed cities
+
+	AliveDefenders     int `json:"alive_defenders"`     // Number of alive defenders
+	DestroyedDefenders int `json:"destroyed_defenders"` // Number of defenders killed by aliens
 }
@@ -2,6 +2,8 @@ package world
 
 import (
 	"fmt"
+	"log"
+	"sort"
 	"strings"
 
 	"github.com/AzraelSec/mad-aliens/pkg/alien"
@@ -10,13 +12,17 @@ import (
 )
 
 func NewWorld(cities CityMap, links LinkMap, aliens AliensMap) *World {
-	return &World{
-		Cities:          cities,
-		Links:           links,
-		Aliens:          aliens,
-		StuckAliens:     0,
-		DestroyedAliens: 0,
+	w := &World{
+		Cities: cities,
+		Links:  links,
+		Aliens: aliens,
 	}
+	for id := range aliens {
+		if id >= w.nextId {
+			w.nextId = id + 1
+		}
+	}
+	return w
 }
 
 // Utility method to retrieve an alien pointer
@@ -24,7 +30,7 @@ func (w *World) findAlienPointer(id int) (*alien.Alien, error) {
 	if alien, exists := w.Aliens[id]; exists {
 		return alien, nil
 	} else {
-		return nil, fmt.Errorf("cannot find requested alien: %d", id)
+		return nil, &AlienNotFoundError{Id: id}
 	}
 }
 
@@ -33,21 +39,60 @@ func (w *World) findCityPointer(name string) (*city.City, error) {
 	if city, exists := w.Cities[name]; exists {
 		return city, nil
 	} else {
-		return nil, fmt.Errorf("cannot find requested city: %s", name)
+		return nil, &CityNotFoundError{Name: name}
 	}
 }
 
 // Method that counts the alive aliens.
 // Since destroyed aliens are soft-deleted an additional function is needed to count the alive aliens.
 func (w *World) CountAliveAliens() int {
-	return len(w.Aliens) - w.DestroyedAliens
+	return w.Stats().AliveAliens
+}
+
+// Method that returns the version of the world topology, which changes whenever a city gets destroyed, rebuilt, added
+// or renamed, or a road gets opened or closed, so that anything derived from it can be cached until then.
+// Changes made by poking the maps directly are not accounted for.
+func (w *World) Topology() uint64 {
+	return w.topology
 }
 
-// Method that serialize the world with the same format used to define it
+// Method that computes the world statistics.
+// Instead of keeping counters up to date, they are derived from the aliens and cities state,
+// so that they cannot drift when the same alien gets stuck or destroyed more than once.
+func (w *World) Stats() Stats {
+	var s Stats
+	for _, al := range w.Aliens {
+		switch {
+		case al.IsDefender() && al.Destroyed:
+			s.DestroyedDefenders++
+		case al.IsDefender():
+			s.AliveDefenders++
+		case al.Destroyed:
+			s.DestroyedAliens++
+		case al.Stuck:
+			s.AliveAliens++
+			s.StuckAliens++
+		default:
+			s.AliveAliens++
+		}
+	}
+	for _, c := range w.Cities {
+		if c.Destroyed {
+			s.DestroyedCities++
+		} else {
+			s.SurvivingCities++
+		}
+	}
+	return s
+}
+
+// Method that serialize the world with the same format used to define it.
+// Cities are sorted by name and their roads follow the natural directions order, so that the output is stable.
 func (w *World) String() (o string) {
 	lines := make([]string, 0)
 
-	for name, city := range w.Cities {
+	for _, name := range w.SortedCityNames() {
+		city := w.Cities[name]
 		if city.Destroyed {
 			continue
 		}
@@ -60,16 +105,27 @@ func (w *World) String() (o string) {
 			line := make([]string, 0)
 			anyValid := false
 
-			for direction, arrival := range links {
-				directionStr, err := directionString(direction)
+			for _, direction := range Directions {
+				arrival, exists := links[direction]
+				if !exists {
+					continue
+				}
+				directionStr, err := DirectionString(direction)
 				if err == nil && !arrival.Destroyed {
-					line = append(line, fmt.Sprintf("%s=%s", directionStr, arrival.Name))
+					link := fmt.Sprintf("%s=%s", directionStr, arrival.Name)
+					if attrs := w.Road(name, direction).String(); attrs != "" {
+						link += ":" + attrs
+					}
+					line = append(line, link)
 					anyValid = true
 				}
 			}
 
 			// Only cities having at least one link are printed out
 			if anyValid {
+				if attrs := cityAttributes(city); attrs != "" {
+					name += ":" + attrs
+				}
 				lines = append(lines, fmt.Sprintf("%s %s", name, strings.Join(line, " ")))
 			}
 		}
@@ -85,30 +141,229 @@ func (w *World) String() (o string) {
 // Method that moves an alien into a new random city following the available links.
 // If no moves are available, the alien is stuck.
 func (w *World) RandomlyMove(id int) (bool, error) {
-	alien, err := w.findAlienPointer(id)
+	return w.moveWith(id, UniformMovement{}, utils.Shared)
+}
+
+// Method that moves an alien following its movement strategy.
+// It returns whether the alien left its city: if no moves are available the alien is stuck,
+// otherwise it may still stay put if its strategy decides so.
+// Along roads that take more than a round, the alien leaves its city but stays in transit until it arrives.
+func (w *World) Move(id int) (bool, error) {
+	al, err := w.findAlienPointer(id)
 	if err != nil {
 		return false, err
 	}
+	return w.moveWith(id, w.StrategyOf(al), utils.Shared)
+}
+
+// Method that chooses the next move of an alien following its movement strategy, drawing from the given source,
+// without changing the world: the moves of different aliens can be chosen concurrently, and performed later on
+// with MoveAlong. It returns nil if the alien stays in its city, either because it is stuck, because all the
+// roads are full or because its strategy decides so.
+func (w *World) ChooseMove(id int, r utils.Random) (*Move, error) {
+	al, err := w.movableAlien(id)
+	if err != nil {
+		return nil, err
+	}
+	move, _ := w.chooseMove(al, w.StrategyOf(al), r)
+	return move, nil
+}
+
+// Method that moves an alien along a move chosen in advance with ChooseMove, returning whether it left its city.
+// If the move is not possible anymore (its destination has been destroyed, or its road closed or full), the alien
+// moves following its strategy again, drawing from the given source.
+// A nil move keeps the alien in its city, where it is stuck if no moves are available.
+func (w *World) MoveAlong(id int, move *Move, r utils.Random) (bool, error) {
+	al, err := w.movableAlien(id)
+	if err != nil {
+		return false, err
+	}
+	if move == nil {
+		w.recordStart(al)
+		al.Stuck = len(w.AvailableMoves(al.City.Name)) == 0
+		return false, nil
+	}
+	if to, exists := w.Links[al.City.Name][move.Direction]; !exists || to != move.City || to.Destroyed || !w.roadAvailable(al.City.Name, move.Direction) {
+		return w.moveWith(id, w.StrategyOf(al), r)
+	}
+
+	w.recordStart(al)
+	al.Stuck = false
+	w.moveAlong(al, Move{Direction: move.Direction, City: move.City, Road: w.Road(al.City.Name, move.Direction)})
+	return true, nil
+}
 
+func (w *World) moveWith(id int, strategy MovementStrategy, r utils.Random) (bool, error) {
+	al, err := w.movableAlien(id)
+	if err != nil {
+		return false, err
+	}
+	w.recordStart(al)
+
+	// If no moves are available the alien is stuck, else it moves unless all the roads are full or its strategy
+	// keeps it where it is
+	move, stuck := w.chooseMove(al, strategy, r)
+	al.Stuck = stuck
+	if move == nil {
+		return false, nil
+	}
+	w.moveAlong(al, *move)
+	return true, nil
+}
+
+// Method that retrieves an alien that can leave its city, since it is not in transit
+func (w *World) movableAlien(id int) (*alien.Alien, error) {
+	al, err := w.findAlienPointer(id)
+	if err != nil {
+		return nil, err
+	}
+	if al.InTransit() {
+		return nil, fmt.Errorf("alien %d is in transit", id)
+	}
+	return al, nil
+}
+
+// Number of most recent steps recorded in each alien path by default, so that long invasions do not grow without bound
+const DefaultPathLimit = 100
+
+// Method that returns the max number of steps recorded in each alien path, or zero if unbounded
+func (w *World) pathLimit() int {
+	switch {
+	case w.PathLimit == 0:
+		return DefaultPathLimit
+	case w.PathLimit < 0:
+		return 0
+	}
+	return w.PathLimit
+}
+
+// Method that records the starting city of an alien deployed without one, on its first move
+func (w *World) recordStart(al *alien.Alien) {
+	if len(al.Path) == 0 && al.Visited == nil {
+		al.RecordStep(alien.Step{Round: w.Round, City: al.City.Name}, w.pathLimit())
+	}
+}
+
+// Method that chooses the next move of an alien, without changing the world.
+// It returns nil if the alien stays in its city, along with whether it is stuck.
+func (w *World) chooseMove(al *alien.Alien, strategy MovementStrategy, r utils.Random) (*Move, bool) {
 	// Iterate over linked cities filtering destroyed ones to get the available next moves
-	availableLinks, availableIds := w.Links[alien.City.Name], make([]string, 0)
-	for _, arrival := range availableLinks {
-		if !arrival.Destroyed {
-			availableIds = append(availableIds, arrival.Name)
+	moves := w.AvailableMoves(al.City.Name)
+	if len(moves) == 0 {
+		return nil, true
+	}
+
+	open := make([]Move, 0, len(moves))
+	for _, m := range moves {
+		if w.roadAvailable(al.City.Name, m.Direction) {
+			open = append(open, m)
 		}
 	}
+	if len(open) == 0 {
+		return nil, false
+	}
+	return strategy.Choose(w, al, open, r), false
+}
+
+// Method that makes an alien leave its city along a road.
+// Along roads that take more than a round, the alien stays in transit until it arrives.
+func (w *World) moveAlong(al *alien.Alien, move Move) {
+	w.traverse(al.City.Name, move.Direction)
+	direction, _ := DirectionString(move.Direction)
+	if move.Road.Time > 1 {
+		al.Transit = &alien.Transit{From: al.City, To: move.City, Direction: direction, Remaining: move.Road.Time - 1}
+		return
+	}
+	al.City = move.City
+	al.RecordStep(alien.Step{Round: w.Round, City: al.City.Name, Direction: direction}, w.pathLimit())
+}
+
+// Method that makes an alien in transit travel for another round, returning whether it arrived.
+// An alien arrives even if its destination has been destroyed in the meanwhile.
+func (w *World) Travel(id int) (bool, error) {
+	al, err := w.findAlienPointer(id)
+	if err != nil {
+		return false, err
+	}
+	if !al.InTransit() {
+		return false, fmt.Errorf("alien %d is not in transit", id)
+	}
 
-	if len(availableIds) == 0 {
-		// If no moves are available, the alien is stuck
-		w.StuckAliens++
-		alien.Stuck = true
+	al.Transit.Remaining--
+	if al.Transit.Remaining > 0 {
 		return false, nil
-	} else {
-		// Else the alien moves into a new city
-		idx := utils.RandomInt(len(availableIds))
-		alien.City = w.Cities[availableIds[idx]]
-		return true, nil
 	}
+	al.City = al.Transit.To
+	al.RecordStep(alien.Step{Round: w.Round, City: al.City.Name, Direction: al.Transit.Direction}, w.pathLimit())
+	al.Transit = nil
+	return true, nil
+}
+
+// Method that keeps an alien in its city for a round.
+// Waiting is a choice only when moves are available, so it returns false (and the alien is stuck) otherwise.
+func (w *World) Wait(id int) (bool, error) {
+	al, err := w.findAlienPointer(id)
+	if err != nil {
+		return false, err
+	}
+	al.Stuck = len(w.AvailableMoves(al.City.Name)) == 0
+	return !al.Stuck, nil
+}
+
+// Method that returns the roads leaving a city toward non-destroyed cities, in the natural directions order
+func (w *World) AvailableMoves(name string) []Move {
+	moves := make([]Move, 0, len(Directions))
+	for _, d := range Directions {
+		if arrival, exists := w.Links[name][d]; exists && !arrival.Destroyed {
+			moves = append(moves, Move{Direction: d, City: arrival, Road: w.Road(name, d)})
+		}
+	}
+	return moves
+}
+
+// Method that returns the movement strategy an alien follows
+func (w *World) StrategyOf(a *alien.Alien) MovementStrategy {
+	if s, exists := w.Strategies[a.Id]; exists {
+		return s
+	}
+	if a.IsDefender() && w.DefenderStrategy != nil {
+		return w.DefenderStrategy
+	}
+	if s, exists := w.FactionStrategies[a.Faction]; exists && a.Faction != "" {
+		return s
+	}
+	if w.DefaultStrategy != nil {
+		return w.DefaultStrategy
+	}
+	return UniformMovement{}
+}
+
+// Method that sets the movement strategy of a single alien
+func (w *World) SetStrategy(id int, s MovementStrategy) {
+	if w.Strategies == nil {
+		w.Strategies = make(map[int]MovementStrategy)
+	}
+	w.Strategies[id] = s
+}
+
+// Method that returns the recorded path of an alien
+func (w *World) Path(id int) ([]alien.Step, error) {
+	al, err := w.findAlienPointer(id)
+	if err != nil {
+		return nil, err
+	}
+	return al.Path, nil
+}
+
+// Method that returns the number of cities visited by at least one alien, and the total number of cities
+func (w *World) Coverage() (int, int) {
+	visited := make(map[string]bool)
+	for _, al := range w.Aliens {
+		for name := range al.Visited {
+			visited[name] = true
+		}
+	}
+	return len(visited), len(w.Cities)
 }
 
 // Method that soft-delete a group of cities
@@ -116,8 +371,11 @@ func (w *World) DestroyCities(names []string) error {
 	for _, name := range names {
 		if city, err := w.findCityPointer(name); err != nil {
 			return err
-		} else {
+		} else if !city.Destroyed {
+			city, _ = w.writableCity(name)
+			w.saveOriginal(city)
 			city.Destroyed = true
+			w.markDestroyed(city)
 		}
 	}
 	return nil
@@ -129,15 +387,90 @@ func (w *World) DestroyAliens(ids []int) error {
 		if al, err := w.findAlienPointer(id); err != nil {
 			return err
 		} else {
-			w.DestroyedAliens++
 			al.Destroyed = true
 		}
 	}
 	return nil
 }
 
+// Method that returns the aliens identification numbers in increasing order
+func (w *World) SortedAlienIds() []int {
+	ids := make([]int, 0, len(w.Aliens))
+	for id := range w.Aliens {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Method that assigns identities to the aliens, in increasing order of identification number.
+// If there are more aliens than identities and generate is set, the remaining aliens get
+// a random name that is not used by any other alien. The location of each named alien is logged again, by name.
+func (w *World) AssignIdentities(identities []alien.Identity, generate bool) {
+	used := make(map[string]bool)
+	for _, id := range identities {
+		used[id.Name] = true
+	}
+
+	for i, id := range w.SortedAlienIds() {
+		switch {
+		case i < len(identities):
+			w.Aliens[id].SetIdentity(identities[i])
+		case generate:
+			name := alien.RandomName()
+			for used[name] {
+				name = alien.RandomName()
+			}
+			used[name] = true
+			w.Aliens[id].Name = name
+		default:
+			continue
+		}
+		if al := w.Aliens[id]; al.City != nil {
+			log.Printf("%s is located at %s", al, al.City.Name)
+		}
+	}
+}
+
 // Utility method to retrieve a city from the cities map
 func (w *World) GetCity(name string) (city.City, error) {
 	city, err := w.findCityPointer(name)
 	return *city, err
 }
+
+// Method that deploys a new alien in a city, with the first identification number not used yet
+func (w *World) SpawnAlien(name string) (*alien.Alien, error) {
+	return w.spawnAgent(name, alien.ALIEN)
+}
+
+// Method that deploys a new defender in a city, with the first identification number not used yet
+func (w *World) SpawnDefender(name string) (*alien.Alien, error) {
+	return w.spawnAgent(name, alien.DEFENDER)
+}
+
+func (w *World) spawnAgent(name string, kind alien.Kind) (*alien.Alien, error) {
+	c, err := w.findCityPointer(name)
+	if err != nil {
+		return nil, err
+	}
+	if c.Destroyed {
+		return nil, &CityDestroyedError{Name: name}
+	}
+
+	// Aliens added by poking the map directly may have taken the next id already
+	for {
+		if _, exists := w.Aliens[w.nextId]; !exists {
+			break
+		}
+		w.nextId++
+	}
+	id := w.nextId
+	w.nextId++
+
+	al := alien.NewAlien(id, nil)
+	al.Kind = kind
+	al.City = c
+	al.RecordStep(alien.Step{Round: w.Round, City: name}, w.pathLimit())
+	w.Aliens[id] = al
+	return al, nil
+}
@@ -1,6 +1,7 @@
 package world
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/AzraelSec/mad-aliens/pkg/alien"
@@ -135,8 +136,190 @@ func TestRandomlyMove(t *testing.T) {
 			t.Error(err)
 		}
 
-		if w.StuckAliens != test.expectedStuckNum {
-			t.Errorf("Expected %v stuck aliens, got %v", test.expectedStuckNum, w.StuckAliens)
+		if stuck := w.Stats().StuckAliens; stuck != test.expectedStuckNum {
+			t.Errorf("Expected %v stuck aliens, got %v", test.expectedStuckNum, stuck)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	var (
+		a      = city.NewCity("A")
+		b      = city.NewCity("B")
+		stuck  = alien.NewAlien(0, a)
+		mover  = alien.NewAlien(1, b)
+		victim = alien.NewAlien(2, b)
+	)
+
+	w := NewWorld(
+		CityMap{"A": a, "B": b},
+		LinkMap{"B": map[Direction]*city.City{West: a}},
+		AliensMap{stuck.Id: stuck, mover.Id: mover, victim.Id: victim},
+	)
+
+	// Stuck aliens are counted once, no matter how many times they try to move
+	for i := 0; i < 3; i++ {
+		if _, err := w.RandomlyMove(stuck.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.RandomlyMove(mover.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	// Destroying the same alien twice does not change the counters
+	for i := 0; i < 2; i++ {
+		if err := w.DestroyAliens([]int{victim.Id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.DestroyCities([]string{"B"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Stats{
+		AliveAliens:     2,
+		StuckAliens:     1,
+		DestroyedAliens: 1,
+		SurvivingCities: 1,
+		DestroyedCities: 1,
+	}
+	if got := w.Stats(); got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+
+	// Stuck aliens that die are not stuck anymore
+	if err := w.DestroyAliens([]int{stuck.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Stats(); got.StuckAliens != 0 || got.AliveAliens != 1 || got.DestroyedAliens != 2 {
+		t.Errorf("Unexpected stats %+v", got)
+	}
+}
+
+func TestAssignFactions(t *testing.T) {
+	var tests = []struct {
+		nAliens int
+		shares  string
+		wanted  map[string]int
+	}{
+		{10, "red=0.5,blue=0.5", map[string]int{"red": 5, "blue": 5}},
+		{10, "red=2,blue=1,green=1", map[string]int{"red": 5, "blue": 3, "green": 2}},
+		{3, "red=1,blue=1", map[string]int{"red": 2, "blue": 1}},
+		{4, "", map[string]int{"": 4}},
+	}
+
+	for _, test := range tests {
+		aliens := AliensMap{}
+		for i := 0; i < test.nAliens; i++ {
+			aliens[i] = alien.NewAlien(i, cityA)
+		}
+		w := NewWorld(CityMap{"A": cityA}, LinkMap{}, aliens)
+
+		shares, err := ParseFactionShares(test.shares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.AssignFactions(shares)
+
+		counts := make(map[string]int)
+		for _, a := range w.Aliens {
+			counts[a.Faction]++
+		}
+		for faction, n := range test.wanted {
+			if counts[faction] != n {
+				t.Errorf("%s: expected %d %s aliens, got %d", test.shares, n, faction, counts[faction])
+			}
+		}
+	}
+
+	if _, err := ParseFactionShares("red=x"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestWait(t *testing.T) {
+	var (
+		a      = city.NewCity("A")
+		b      = city.NewCity("B")
+		waiter = alien.NewAlien(0, a)
+		stuck  = alien.NewAlien(1, b)
+	)
+	w := NewWorld(
+		CityMap{"A": a, "B": b},
+		LinkMap{"A": map[Direction]*city.City{North: b}},
+		AliensMap{waiter.Id: waiter, stuck.Id: stuck},
+	)
+
+	if waited, err := w.Wait(waiter.Id); err != nil || !waited || waiter.Stuck || waiter.City != a {
+		t.Errorf("Expected the alien to wait in A, got waited %t (%v)", waited, err)
+	}
+
+	// An alien with no available roads cannot choose to wait
+	if waited, err := w.Wait(stuck.Id); err != nil || waited || !stuck.Stuck {
+		t.Errorf("Expected the alien to be stuck, got waited %t (%v)", waited, err)
+	}
+}
+
+func TestSpawnAgents(t *testing.T) {
+	var (
+		a = city.NewCity("A")
+		b = city.NewCity("B")
+	)
+	b.Destroyed = true
+	w := NewWorld(CityMap{"A": a, "B": b}, LinkMap{}, AliensMap{3: alien.NewAlien(3, a)})
+
+	defender, err := w.SpawnDefender("A")
+	if err != nil || defender.Id != 4 || !defender.IsDefender() || defender.City != a {
+		t.Errorf("Unexpected defender %+v (%v)", defender, err)
+	}
+	if _, err := w.SpawnAlien("B"); err == nil {
+		t.Errorf("Expected error spawning in a destroyed city, got nil")
+	}
+	if _, err := w.SpawnAlien("C"); err == nil {
+		t.Errorf("Expected error spawning in an unknown city, got nil")
+	}
+
+	// Defenders are counted apart from the aliens
+	if s := w.Stats(); s.AliveAliens != 1 || s.AliveDefenders != 1 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+
+	// Ids keep growing in forks, skipping the ones taken by poking the map directly
+	fork := w.Fork()
+	fork.Aliens[5] = alien.NewAlien(5, fork.Cities["A"])
+	for world, id := range map[*World]int{w: 5, fork: 6} {
+		if al, err := world.SpawnAlien("A"); err != nil || al.Id != id {
+			t.Errorf("Expected alien %d, got %+v (%v)", id, al, err)
+		}
+	}
+}
+
+func TestPathLimit(t *testing.T) {
+	var tests = []struct {
+		limit  int
+		wanted int
+	}{
+		{0, DefaultPathLimit},
+		{5, 5},
+		{-1, 2 * DefaultPathLimit},
+	}
+
+	for _, test := range tests {
+		w, err := Parse(strings.NewReader("A north=B\nB south=A"), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.PathLimit = test.limit
+
+		// The starting city is recorded along with the first move
+		for i := 0; i < 2*DefaultPathLimit-1; i++ {
+			if _, err := w.Move(0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if al := w.Aliens[0]; len(al.Path) != test.wanted || al.Coverage() != 2 {
+			t.Errorf("limit %d: expected %d steps, got %d", test.limit, test.wanted, len(al.Path))
 		}
 	}
 }